@@ -0,0 +1,243 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import "math"
+
+// sortedSampleAlphaInv is the ratio of remaining records to remaining selections
+// below which Method A is faster than Method D.
+const sortedSampleAlphaInv = 13
+
+// SortedSample generates a uniformly distributed pseudo-random k-subset of the integers
+// in the half-open interval [0, n), in increasing order, one element at a time.
+//
+// SortedSample implements Vitter's Algorithm D (with Method A for the dense part of the sample):
+// it needs O(1) memory and O(k) expected time, regardless of n.
+//
+// SortedSample is not safe for concurrent use.
+type SortedSample struct {
+	r      *Rand
+	n      uint64 // remaining records
+	k      uint64 // remaining selections
+	next   uint64 // next record
+	vprime float64
+	useA   bool
+}
+
+// NewSortedSample returns a generator of a sorted uniformly distributed pseudo-random k-subset
+// of the integers in the half-open interval [0, n), that uses r as the source of randomness.
+// It panics if k > n.
+func NewSortedSample(r *Rand, n uint64, k uint64) *SortedSample {
+	if k > n {
+		panic("invalid argument to NewSortedSample")
+	}
+	s := &SortedSample{r: r, n: n, k: k}
+	if k > 0 {
+		s.vprime = math.Exp(math.Log(r.Float64Open()) / float64(k))
+	}
+	return s
+}
+
+// Next returns the next element of the subset, or (0, false) when all k elements have been returned.
+func (s *SortedSample) Next() (uint64, bool) {
+	if s.k == 0 {
+		return 0, false
+	}
+	var skip uint64
+	switch {
+	case s.k == 1:
+		skip = s.r.Uint64n(s.n)
+	case s.useA || float64(s.n) <= sortedSampleAlphaInv*float64(s.k):
+		s.useA = true
+		skip = s.skipA()
+	default:
+		skip = s.skipD()
+	}
+	v := s.next + skip
+	s.next = v + 1
+	s.n -= skip + 1
+	s.k--
+	return v, true
+}
+
+// skipA returns the number of records to skip before the next selected one, by sequential search (Method A).
+func (s *SortedSample) skipA() uint64 {
+	v := s.r.Float64()
+	top := float64(s.n - s.k)
+	nreal := float64(s.n)
+	quot := top / nreal
+	skip := uint64(0)
+	for quot > v {
+		skip++
+		top--
+		nreal--
+		quot *= top / nreal
+	}
+	return skip
+}
+
+// skipD returns the number of records to skip before the next selected one, by rejection (Method D).
+func (s *SortedSample) skipD() uint64 {
+	n := float64(s.n)
+	k := float64(s.k)
+	kinv := 1 / k
+	k1inv := 1 / (k - 1)
+	qu1 := n - k + 1
+	for {
+		var x, sk float64
+		for {
+			x = n * (1 - s.vprime)
+			sk = math.Floor(x)
+			if sk < qu1 {
+				break
+			}
+			s.vprime = math.Exp(math.Log(s.r.Float64Open()) * kinv)
+		}
+		u := s.r.Float64Open()
+		y1 := math.Exp(math.Log(u*n/qu1) * k1inv)
+		s.vprime = y1 * (1 - x/n) * (qu1 / (qu1 - sk))
+		if s.vprime <= 1 {
+			return uint64(sk)
+		}
+		y2 := 1.0
+		top := n - 1
+		var bottom, limit float64
+		if k-1 > sk {
+			bottom = n - k
+			limit = n - sk
+		} else {
+			bottom = n - sk - 1
+			limit = qu1
+		}
+		for t := n - 1; t >= limit; t-- {
+			y2 = y2 * top / bottom
+			top--
+			bottom--
+		}
+		if n/(n-x) >= y1*math.Exp(math.Log(y2)*k1inv) {
+			s.vprime = math.Exp(math.Log(s.r.Float64Open()) * k1inv)
+			return uint64(sk)
+		}
+		s.vprime = math.Exp(math.Log(s.r.Float64Open()) * kinv)
+	}
+}
+
+// Composition returns, as a slice of m ints, a uniformly distributed pseudo-random composition of total
+// into m positive parts: every way to write total as an ordered sum of m positive integers is equally likely.
+// It panics if m < 1 or total < m.
+func Composition(r *Rand, total int, m int) []int {
+	if m < 1 || total < m {
+		panic("invalid argument to Composition")
+	}
+	// cut [1, total) at m-1 distinct points
+	p := make([]int, m)
+	s := NewSortedSample(r, uint64(total-1), uint64(m-1))
+	prev := 0
+	for i := 0; i < m-1; i++ {
+		c, _ := s.Next()
+		p[i] = int(c) + 1 - prev
+		prev = int(c) + 1
+	}
+	p[m-1] = total - prev
+	return p
+}
+
+// WeakComposition returns, as a slice of m ints, a uniformly distributed pseudo-random composition of total
+// into m non-negative parts: every way to write total as an ordered sum of m non-negative integers is equally likely.
+// It panics if m < 1 or total < 0.
+func WeakComposition(r *Rand, total int, m int) []int {
+	if m < 1 || total < 0 {
+		panic("invalid argument to WeakComposition")
+	}
+	// stars and bars: choose positions of m-1 bars among total+m-1 places
+	p := make([]int, m)
+	s := NewSortedSample(r, uint64(total+m-1), uint64(m-1))
+	prev := -1
+	for i := 0; i < m-1; i++ {
+		c, _ := s.Next()
+		p[i] = int(c) - prev - 1
+		prev = int(c)
+	}
+	p[m-1] = total + m - 2 - prev
+	return p
+}
+
+// Partition returns a uniformly distributed pseudo-random partition of n: a slice of positive integers
+// in non-increasing order that sum to n, every such slice being equally likely. It panics if n < 0.
+//
+// Partition implements the algorithm of Nijenhuis and Wilf. It computes a table of partition numbers
+// in O(n^1.5) time; they are stored in floating point, scaled to avoid overflow, so the result
+// is unbiased up to the floating-point rounding errors.
+func Partition(r *Rand, n int) []int {
+	if n < 0 {
+		panic("invalid argument to Partition")
+	}
+	q, e := partitionTable(n)
+	ratio := func(a int, b int) float64 { // p(a) / p(b)
+		return math.Ldexp(q[a]/q[b], e[a]-e[b])
+	}
+
+	var parts []int
+	for m := n; m > 0; {
+		// choose (d, j) with probability d*p(m-j*d) / (m*p(m)), then add j parts equal to d
+		z := r.Float64() * float64(m)
+		d, j := 1, 1
+	search:
+		for d = 1; d <= m; d++ {
+			for j = 1; j*d <= m; j++ {
+				z -= float64(d) * ratio(m-j*d, m)
+				if z < 0 {
+					break search
+				}
+			}
+		}
+		if d > m { // rounding errors accumulated to more than z; choose the last pair
+			d, j = m, 1
+		}
+		for i := 0; i < j; i++ {
+			parts = append(parts, d)
+		}
+		m -= j * d
+	}
+	// parts of the same size are added together, so insertion sort does few moves
+	for i := 1; i < len(parts); i++ {
+		for k := i; k > 0 && parts[k-1] < parts[k]; k-- {
+			parts[k-1], parts[k] = parts[k], parts[k-1]
+		}
+	}
+	return parts
+}
+
+// partitionTable returns partition numbers p(m) = q[m] * 2^e[m] for m in [0, n],
+// computed with Euler's pentagonal number recurrence.
+func partitionTable(n int) ([]float64, []int) {
+	q := make([]float64, n+1)
+	e := make([]int, n+1)
+	c := math.Pi * math.Sqrt(2.0/3) / math.Ln2 // log2 p(m) ≈ c*sqrt(m)
+	q[0] = 1
+	for m := 1; m <= n; m++ {
+		e[m] = int(c * math.Sqrt(float64(m)))
+		var sum float64
+		for k := 1; ; k++ {
+			g1 := k * (3*k - 1) / 2
+			if g1 > m {
+				break
+			}
+			t := math.Ldexp(q[m-g1], e[m-g1]-e[m])
+			if g2 := g1 + k; g2 <= m {
+				t += math.Ldexp(q[m-g2], e[m-g2]-e[m])
+			}
+			if k%2 == 1 {
+				sum += t
+			} else {
+				sum -= t
+			}
+		}
+		q[m] = sum
+	}
+	return q, e
+}
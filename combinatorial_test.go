@@ -0,0 +1,184 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"fmt"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/kokizzu/rand"
+)
+
+func BenchmarkSortedSample(b *testing.B) {
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s := rand.NewSortedSample(r, 1<<40, small)
+		for _, ok := s.Next(); ok; _, ok = s.Next() {
+		}
+	}
+}
+
+func BenchmarkPartition(b *testing.B) {
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		rand.Partition(r, small)
+	}
+}
+
+func TestSortedSample(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		r := rand.New(rapid.Uint64().Draw(t, "seed").(uint64))
+		n := rapid.Uint64().Draw(t, "n").(uint64)
+		k := rapid.Uint64Range(0, 1000).Draw(t, "k").(uint64)
+		if k > n {
+			k = n
+		}
+		s := rand.NewSortedSample(r, n, k)
+		var prev uint64
+		for i := uint64(0); ; i++ {
+			v, ok := s.Next()
+			if !ok {
+				if i != k {
+					t.Fatalf("got %v elements instead of %v", i, k)
+				}
+				break
+			}
+			if v >= n || (i > 0 && v <= prev) {
+				t.Fatalf("got element %v after %v (n = %v)", v, prev, n)
+			}
+			prev = v
+		}
+	})
+}
+
+func TestSortedSample_Uniformity(t *testing.T) {
+	const samples = 1 << 17
+	r := rand.New(1)
+	for _, c := range []struct{ n, k uint64 }{{6, 3}, {40, 2}, {100, 3}} {
+		counts := map[string]int{}
+		for i := 0; i < samples; i++ {
+			s := rand.NewSortedSample(r, c.n, c.k)
+			var key []uint64
+			for v, ok := s.Next(); ok; v, ok = s.Next() {
+				key = append(key, v)
+			}
+			counts[fmt.Sprint(key)]++
+		}
+		subsets := 1.0
+		for i := uint64(0); i < c.k; i++ {
+			subsets = subsets * float64(c.n-i) / float64(i+1)
+		}
+		checkUniformCounts(t, counts, int(subsets), samples)
+	}
+}
+
+// checkUniformCounts checks that counts of the observed outcomes are consistent
+// with a uniform distribution over the given number of outcomes.
+func checkUniformCounts(t *testing.T, counts map[string]int, outcomes int, samples int) {
+	t.Helper()
+	if len(counts) > outcomes {
+		t.Fatalf("got %v distinct outcomes instead of %v", len(counts), outcomes)
+	}
+	c := make([]int, outcomes)
+	expected := make([]float64, outcomes)
+	i := 0
+	for _, v := range counts {
+		c[i] = v
+		i++
+	}
+	for i := range expected {
+		expected[i] = float64(samples) / float64(outcomes)
+	}
+	checkChiSquared(t, c, expected)
+}
+
+func TestComposition(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		r := rand.New(rapid.Uint64().Draw(t, "seed").(uint64))
+		m := rapid.IntRange(1, small).Draw(t, "m").(int)
+		total := rapid.IntRange(m, 10*small).Draw(t, "total").(int)
+		weak := rapid.Bool().Draw(t, "weak").(bool)
+		var p []int
+		if weak {
+			p = rand.WeakComposition(r, total-m, m)
+		} else {
+			p = rand.Composition(r, total, m)
+		}
+		if len(p) != m {
+			t.Fatalf("got %v parts instead of %v", len(p), m)
+		}
+		sum := 0
+		for _, v := range p {
+			if v < 0 || (!weak && v == 0) {
+				t.Fatalf("got invalid part %v in %v", v, p)
+			}
+			sum += v
+		}
+		if weak {
+			sum += m
+		}
+		if sum != total {
+			t.Fatalf("parts %v do not sum to the total", p)
+		}
+	})
+}
+
+func TestComposition_Uniformity(t *testing.T) {
+	const samples = 1 << 16
+	r := rand.New(1)
+	counts := map[string]int{}
+	weakCounts := map[string]int{}
+	for i := 0; i < samples; i++ {
+		counts[fmt.Sprint(rand.Composition(r, 7, 3))]++
+		weakCounts[fmt.Sprint(rand.WeakComposition(r, 4, 3))]++
+	}
+	checkUniformCounts(t, counts, 15, samples)
+	checkUniformCounts(t, weakCounts, 15, samples)
+}
+
+func TestPartition(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		r := rand.New(rapid.Uint64().Draw(t, "seed").(uint64))
+		n := rapid.IntRange(0, 10*small).Draw(t, "n").(int)
+		p := rand.Partition(r, n)
+		sum := 0
+		for i, v := range p {
+			if v <= 0 || (i > 0 && v > p[i-1]) {
+				t.Fatalf("got invalid part %v in %v", v, p)
+			}
+			sum += v
+		}
+		if sum != n {
+			t.Fatalf("parts %v do not sum to %v", p, n)
+		}
+	})
+}
+
+func TestPartition_Uniformity(t *testing.T) {
+	const samples = 1 << 16
+	r := rand.New(1)
+	for _, c := range []struct{ n, partitions int }{{6, 11}, {10, 42}} {
+		counts := map[string]int{}
+		for i := 0; i < samples; i++ {
+			counts[fmt.Sprint(rand.Partition(r, c.n))]++
+		}
+		checkUniformCounts(t, counts, c.partitions, samples)
+	}
+}
+
+func TestPartition_Large(t *testing.T) {
+	p := rand.Partition(rand.New(1), 100000)
+	sum := 0
+	for _, v := range p {
+		sum += v
+	}
+	if sum != 100000 {
+		t.Fatalf("parts sum to %v instead of 100000", sum)
+	}
+}
@@ -6,6 +6,11 @@
 
 package rand_test
 
+import (
+	"math"
+	"testing"
+)
+
 const (
 	tiny  = 52
 	small = 1000
@@ -19,4 +24,29 @@ var (
 	sinkUint64  uint64
 	sinkFloat64 float64
 	sinkFloat32 float32
+	sinkBool    bool
 )
+
+// checkChiSquared fails the test if the observed counts are unlikely to come
+// from the distribution with expected counts, using Pearson's χ2 statistic.
+// With the fixed seeds used by tests, a 6σ threshold of the normal approximation
+// keeps the tests robust while still catching real bias.
+func checkChiSquared(t testing.TB, counts []int, expected []float64) {
+	t.Helper()
+	var chiSq float64
+	df := -1
+	for i, e := range expected {
+		if e == 0 {
+			if counts[i] != 0 {
+				t.Fatalf("got %v occurrences of impossible value %v", counts[i], i)
+			}
+			continue
+		}
+		obs := float64(counts[i])
+		chiSq += (obs - e) * (obs - e) / e
+		df++
+	}
+	if limit := float64(df) + 6*math.Sqrt(2*float64(df)); chiSq > limit {
+		t.Errorf("χ2 = %.1f, DoF = %v, limit %.1f", chiSq, df, limit)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"fmt"
+	"math"
+)
+
+// Continuous is a continuous probability distribution over real numbers.
+//
+// Distributions in this package are immutable parameter objects: the setup work is done once by the constructor,
+// and a distribution can be shared between goroutines using distinct generators.
+type Continuous interface {
+	// Sample returns a pseudo-random number with the distribution, using r as the source of randomness.
+	Sample(r *Rand) float64
+	// PDF returns the probability density function at x.
+	PDF(x float64) float64
+	// CDF returns the probability that a sample is less than or equal to x.
+	CDF(x float64) float64
+	// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+	Quantile(p float64) float64
+	// Mean returns the expected value, which can be infinite or NaN if it does not exist.
+	Mean() float64
+	// Variance returns the variance, which can be infinite or NaN if it does not exist.
+	Variance() float64
+}
+
+// Discrete is a discrete probability distribution over non-negative integers.
+//
+// Like [Continuous] distributions, discrete distributions are immutable,
+// and can be shared between goroutines using distinct generators.
+type Discrete interface {
+	// Sample returns a pseudo-random number with the distribution, using r as the source of randomness.
+	Sample(r *Rand) uint64
+	// PMF returns the probability that a sample is equal to k.
+	PMF(k uint64) float64
+	// CDF returns the probability that a sample is less than or equal to k.
+	CDF(k uint64) float64
+	// Quantile returns the smallest k such that CDF(k) >= p. It panics if p is not in [0, 1].
+	Quantile(p float64) uint64
+	// Mean returns the expected value, which can be infinite if it does not exist.
+	Mean() float64
+	// Variance returns the variance, which can be infinite if it does not exist.
+	Variance() float64
+}
+
+var (
+	_ Continuous = (*Normal)(nil)
+	_ Continuous = (*Exponential)(nil)
+	_ Discrete   = (*Zipf)(nil)
+)
+
+func isFinite(x float64) bool {
+	return !math.IsInf(x, 0) && !math.IsNaN(x)
+}
+
+func checkQuantile(p float64) {
+	if !(p >= 0 && p <= 1) {
+		panic("invalid argument to Quantile")
+	}
+}
+
+// Normal is the normal (Gaussian) distribution.
+type Normal struct {
+	mean float64
+	sd   float64
+}
+
+// NewNormal returns the normal distribution with the given mean and standard deviation.
+// It returns an error unless mean is finite, and sd is finite and positive.
+func NewNormal(mean float64, sd float64) (*Normal, error) {
+	if !isFinite(mean) || !isFinite(sd) || sd <= 0 {
+		return nil, fmt.Errorf("rand: invalid normal distribution parameters mean=%v sd=%v", mean, sd)
+	}
+	return &Normal{mean: mean, sd: sd}, nil
+}
+
+// Sample returns a normally distributed pseudo-random number, using [Rand.NormFloat64].
+func (n *Normal) Sample(r *Rand) float64 {
+	return n.mean + n.sd*r.NormFloat64()
+}
+
+// PDF returns the probability density function at x.
+func (n *Normal) PDF(x float64) float64 {
+	z := (x - n.mean) / n.sd
+	return math.Exp(-z*z/2) / (n.sd * math.Sqrt(2*math.Pi))
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (n *Normal) CDF(x float64) float64 {
+	return math.Erfc(-(x-n.mean)/(n.sd*math.Sqrt2)) / 2
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (n *Normal) Quantile(p float64) float64 {
+	checkQuantile(p)
+	return n.mean - n.sd*math.Sqrt2*math.Erfcinv(2*p)
+}
+
+// Mean returns the expected value.
+func (n *Normal) Mean() float64 {
+	return n.mean
+}
+
+// Variance returns the variance.
+func (n *Normal) Variance() float64 {
+	return n.sd * n.sd
+}
+
+// Exponential is the exponential distribution.
+type Exponential struct {
+	rate float64
+}
+
+// NewExponential returns the exponential distribution with the given rate (the inverse of the mean).
+// It returns an error unless rate is finite and positive.
+func NewExponential(rate float64) (*Exponential, error) {
+	if !isFinite(rate) || rate <= 0 {
+		return nil, fmt.Errorf("rand: invalid exponential distribution rate %v", rate)
+	}
+	return &Exponential{rate: rate}, nil
+}
+
+// Sample returns an exponentially distributed pseudo-random number, using [Rand.ExpFloat64].
+func (e *Exponential) Sample(r *Rand) float64 {
+	return r.ExpFloat64() / e.rate
+}
+
+// PDF returns the probability density function at x.
+func (e *Exponential) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return e.rate * math.Exp(-e.rate*x)
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (e *Exponential) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return -math.Expm1(-e.rate * x)
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (e *Exponential) Quantile(p float64) float64 {
+	checkQuantile(p)
+	return -math.Log1p(-p) / e.rate
+}
+
+// Mean returns the expected value.
+func (e *Exponential) Mean() float64 {
+	return 1 / e.rate
+}
+
+// Variance returns the variance.
+func (e *Exponential) Variance() float64 {
+	return 1 / (e.rate * e.rate)
+}
@@ -0,0 +1,517 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	hypergeometricH2PEMode = 10 // H2PE is faster than inversion from here on
+	h2peDeltaL             = 0.0078
+	h2peDeltaU             = 0.0034
+)
+
+var (
+	_ Discrete = (*Geometric)(nil)
+	_ Discrete = (*NegativeBinomial)(nil)
+	_ Discrete = (*Hypergeometric)(nil)
+)
+
+// Geometric is the geometric distribution: the number of failures before the first success
+// in independent trials with success probability p.
+type Geometric struct {
+	p   float64
+	l1p float64 // log(1-p)
+}
+
+// NewGeometric returns the geometric distribution with success probability p.
+// It returns an error unless p is in (0, 1].
+func NewGeometric(p float64) (*Geometric, error) {
+	if !(p > 0 && p <= 1) {
+		return nil, fmt.Errorf("rand: invalid geometric distribution probability %v", p)
+	}
+	return &Geometric{p: p, l1p: math.Log1p(-p)}, nil
+}
+
+// Sample returns a geometrically distributed pseudo-random number, as a scaled [Rand.ExpFloat64]
+// rounded down. It needs O(1) time regardless of p, and returns [math.MaxUint64] in place of
+// values that do not fit into uint64.
+func (d *Geometric) Sample(r *Rand) uint64 {
+	x := math.Floor(r.ExpFloat64() / -d.l1p)
+	if x >= 0x1p64 {
+		return math.MaxUint64
+	}
+	return uint64(x)
+}
+
+// PMF returns the probability that a sample is equal to k.
+func (d *Geometric) PMF(k uint64) float64 {
+	if d.p == 1 {
+		if k == 0 {
+			return 1
+		}
+		return 0
+	}
+	return d.p * math.Exp(float64(k)*d.l1p)
+}
+
+// CDF returns the probability that a sample is less than or equal to k.
+func (d *Geometric) CDF(k uint64) float64 {
+	return -math.Expm1((float64(k) + 1) * d.l1p)
+}
+
+// Quantile returns the smallest k such that CDF(k) >= p. It panics if p is not in [0, 1].
+func (d *Geometric) Quantile(p float64) uint64 {
+	checkQuantile(p)
+	x := math.Max(math.Ceil(math.Log1p(-p)/d.l1p)-1, 0)
+	if x >= 0x1p64 {
+		return math.MaxUint64
+	}
+	// correct the rounding errors of the closed form
+	k := uint64(x)
+	for k > 0 && d.CDF(k-1) >= p {
+		k--
+	}
+	for k < math.MaxUint64 && d.CDF(k) < p {
+		k++
+	}
+	return k
+}
+
+// Mean returns the expected value.
+func (d *Geometric) Mean() float64 {
+	return (1 - d.p) / d.p
+}
+
+// Variance returns the variance.
+func (d *Geometric) Variance() float64 {
+	return (1 - d.p) / (d.p * d.p)
+}
+
+// NegativeBinomial is the negative binomial distribution: the number of failures before
+// r successes in independent trials with success probability p. The number of successes r
+// does not have to be an integer.
+type NegativeBinomial struct {
+	r float64
+	p float64
+}
+
+// NewNegativeBinomial returns the negative binomial distribution with r successes and success probability p.
+// It returns an error unless r is finite and positive, and p is in (0, 1].
+func NewNegativeBinomial(r float64, p float64) (*NegativeBinomial, error) {
+	if !isFinite(r) || r <= 0 || !(p > 0 && p <= 1) {
+		return nil, fmt.Errorf("rand: invalid negative binomial distribution parameters r=%v p=%v", r, p)
+	}
+	return &NegativeBinomial{r: r, p: p}, nil
+}
+
+// Sample returns a negative binomially distributed pseudo-random number,
+// as a Poisson distributed number with a gamma distributed mean.
+func (d *NegativeBinomial) Sample(r *Rand) uint64 {
+	lambda := gammaSample(r, d.r) * (1 - d.p) / d.p
+	if !(lambda > 0) {
+		return 0
+	}
+	var p Poisson
+	p.init(math.Min(lambda, poissonMaxLambda))
+	return p.Sample(r)
+}
+
+// PMF returns the probability that a sample is equal to k.
+func (d *NegativeBinomial) PMF(k uint64) float64 {
+	x := float64(k)
+	return d.r / (x + d.r) * dbinomRaw(d.r, x+d.r, d.p, 1-d.p)
+}
+
+// CDF returns the probability that a sample is less than or equal to k.
+func (d *NegativeBinomial) CDF(k uint64) float64 {
+	return betaInc(d.r, float64(k)+1, d.p)
+}
+
+// Quantile returns the smallest k such that CDF(k) >= p. It panics if p is not in [0, 1].
+func (d *NegativeBinomial) Quantile(p float64) uint64 {
+	checkQuantile(p)
+	return searchQuantile(d.CDF, p, math.MaxUint64)
+}
+
+// Mean returns the expected value.
+func (d *NegativeBinomial) Mean() float64 {
+	return d.r * (1 - d.p) / d.p
+}
+
+// Variance returns the variance.
+func (d *NegativeBinomial) Variance() float64 {
+	return d.r * (1 - d.p) / (d.p * d.p)
+}
+
+// Hypergeometric is the hypergeometric distribution: the number of successes in n draws without replacement
+// from a population of size N that contains K successes.
+type Hypergeometric struct {
+	total     uint64
+	successes uint64
+	draws     uint64
+	// the sampler draws k <= N/2 from a population of n1 <= n2 successes and n2 failures
+	n1, n2, k    float64
+	m            float64 // mode
+	minx, maxx   float64
+	complement   bool // k = N - draws
+	swap         bool // n1 = N - successes
+	w            float64
+	xl, xr, a    float64 // a = logF(m)
+	lamdl, lamdr float64
+	p1, p2, p3   float64
+}
+
+// NewHypergeometric returns the hypergeometric distribution of the number of successes in draws draws
+// from a population of size total that contains successes successes.
+// It returns an error if successes > total or draws > total.
+func NewHypergeometric(total uint64, successes uint64, draws uint64) (*Hypergeometric, error) {
+	if successes > total || draws > total {
+		return nil, fmt.Errorf("rand: invalid hypergeometric distribution parameters total=%v successes=%v draws=%v", total, successes, draws)
+	}
+	d := &Hypergeometric{total: total, successes: successes, draws: draws}
+	tn := float64(total)
+	d.n1, d.n2 = float64(successes), float64(total-successes)
+	if d.n1 > d.n2 {
+		d.n1, d.n2 = d.n2, d.n1
+		d.swap = true
+	}
+	d.k = float64(draws)
+	if draws > total-draws {
+		d.k = float64(total - draws)
+		d.complement = true
+	}
+	d.m = math.Floor((d.k + 1) * (d.n1 + 1) / (tn + 2))
+	d.minx = math.Max(0, d.k-d.n2)
+	d.maxx = math.Min(d.n1, d.k)
+	if d.m-d.minx < hypergeometricH2PEMode {
+		d.w = dhyper(d.minx, d.n1, d.n2, d.k)
+		return d, nil
+	}
+	s := math.Sqrt((tn - d.k) * d.k * d.n1 * d.n2 / (tn - 1) / tn / tn)
+	dd := math.Floor(1.5*s) + 0.5
+	d.xl = d.m - dd + 0.5
+	d.xr = d.m + dd + 0.5
+	d.a = d.logF(d.m)
+	kl := math.Exp(d.logF(math.Floor(d.xl)) - d.a)
+	kr := math.Exp(d.logF(math.Floor(d.xr-1)) - d.a)
+	d.lamdl = -math.Log(d.xl * (d.n2 - d.k + d.xl) / (d.n1 - d.xl + 1) / (d.k - d.xl + 1))
+	d.lamdr = -math.Log((d.n1 - d.xr + 1) * (d.k - d.xr + 1) / d.xr / (d.n2 - d.k + d.xr))
+	d.p1 = dd + dd
+	d.p2 = d.p1 + kl/d.lamdl
+	d.p3 = d.p2 + kr/d.lamdr
+	return d, nil
+}
+
+// logF returns the logarithm of the unnormalized probability of x successes for the sampler.
+func (d *Hypergeometric) logF(x float64) float64 {
+	return -lgamma(x+1) - lgamma(d.n1-x+1) - lgamma(d.k-x+1) - lgamma(d.n2-d.k+x+1)
+}
+
+// Sample returns a hypergeometrically distributed pseudo-random number.
+//
+// When the mode is close to the lower end of the support, Sample uses inversion by sequential search (HIN).
+// Otherwise, it uses the H2PE algorithm by Kachitvichyanukul and Schmeiser,
+// which needs O(1) time regardless of the parameters.
+func (d *Hypergeometric) Sample(r *Rand) uint64 {
+	var x float64
+	switch {
+	case d.minx == d.maxx:
+		x = d.maxx
+	case d.m-d.minx < hypergeometricH2PEMode:
+		x = d.hin(r)
+	default:
+		x = d.h2pe(r)
+	}
+	ix := uint64(x)
+	switch {
+	case d.complement && d.swap:
+		return d.draws - (d.total - d.successes) + ix
+	case d.complement:
+		return d.successes - ix
+	case d.swap:
+		return d.draws - ix
+	default:
+		return ix
+	}
+}
+
+func (d *Hypergeometric) hin(r *Rand) float64 {
+	for {
+		p := d.w
+		x := d.minx
+		u := r.Float64()
+		for u > p {
+			u -= p
+			p *= (d.n1 - x) * (d.k - x)
+			x++
+			p /= x * (d.n2 - d.k + x)
+			if x > d.maxx { // rounding errors accumulated; start over
+				break
+			}
+		}
+		if x <= d.maxx {
+			return x
+		}
+	}
+}
+
+func (d *Hypergeometric) h2pe(r *Rand) float64 {
+	n1, n2, k, m := d.n1, d.n2, d.k, d.m
+	for {
+		u := r.Float64() * d.p3
+		v := r.Float64()
+		var x float64
+		switch {
+		case u < d.p1: // rectangular region
+			x = math.Floor(d.xl + u)
+		case u <= d.p2: // left tail
+			x = math.Floor(d.xl + math.Log(v)/d.lamdl)
+			if x < d.minx {
+				continue
+			}
+			v *= (u - d.p1) * d.lamdl
+		default: // right tail
+			x = math.Floor(d.xr - math.Log(v)/d.lamdr)
+			if x > d.maxx {
+				continue
+			}
+			v *= (u - d.p2) * d.lamdr
+		}
+
+		if m < 100 || x <= 50 {
+			// explicit evaluation of f(x) / f(m)
+			f := 1.0
+			if m < x {
+				for i := m + 1; i <= x; i++ {
+					f = f * (n1 - i + 1) * (k - i + 1) / (n2 - k + i) / i
+				}
+			} else if m > x {
+				for i := x + 1; i <= m; i++ {
+					f = f * i * (n2 - k + i) / (n1 - i + 1) / (k - i + 1)
+				}
+			}
+			if v <= f {
+				return x
+			}
+			continue
+		}
+
+		// squeeze using upper and lower bounds on log(f(x) / f(m))
+		y1 := x + 1
+		ym := x - m
+		yn := n1 - x + 1
+		yk := k - x + 1
+		nk := n2 - k + y1
+		rr := -ym / y1
+		s := ym / yn
+		t := ym / yk
+		e := -ym / nk
+		g := yn*yk/(y1*nk) - 1
+		dg := 1.0
+		if g < 0 {
+			dg = 1 + g
+		}
+		gu := g * (1 + g*(-0.5+g/3))
+		gl := gu - 0.25*(g*g*g*g)/dg
+		xm := m + 0.5
+		xn := n1 - m + 0.5
+		xk := k - m + 0.5
+		nm := n2 - k + xm
+		ub := x*gu - m*gl + h2peDeltaU + xm*rr*(1+rr*(-0.5+rr/3)) + xn*s*(1+s*(-0.5+s/3)) +
+			xk*t*(1+t*(-0.5+t/3)) + nm*e*(1+e*(-0.5+e/3))
+		l := math.Log(v)
+		if l > ub {
+			continue
+		}
+		dr := xm * (rr * rr * rr * rr)
+		if rr < 0 {
+			dr /= 1 + rr
+		}
+		ds := xn * (s * s * s * s)
+		if s < 0 {
+			ds /= 1 + s
+		}
+		dt := xk * (t * t * t * t)
+		if t < 0 {
+			dt /= 1 + t
+		}
+		de := nm * (e * e * e * e)
+		if e < 0 {
+			de /= 1 + e
+		}
+		if l < ub-0.25*(dr+ds+dt+de)+(x+m)*(gl-gu)-h2peDeltaL || l <= d.logF(x)-d.a {
+			return x
+		}
+	}
+}
+
+// dhyper returns the probability of x successes in n draws from a population
+// with r successes and b failures, using the saddle point expansion by C. Loader.
+func dhyper(x float64, r float64, b float64, n float64) float64 {
+	if x < 0 || x > r || n-x > b {
+		return 0
+	}
+	if n == 0 {
+		return 1
+	}
+	p := n / (r + b)
+	q := (r + b - n) / (r + b)
+	return dbinomRaw(x, r, p, q) * dbinomRaw(n-x, b, p, q) / dbinomRaw(n, r+b, p, q)
+}
+
+// PMF returns the probability that a sample is equal to k.
+func (d *Hypergeometric) PMF(k uint64) float64 {
+	return dhyper(float64(k), float64(d.successes), float64(d.total-d.successes), float64(d.draws))
+}
+
+// CDF returns the probability that a sample is less than or equal to k.
+//
+// CDF sums the PMF ratios from k towards the nearer tail, so its time grows
+// with the distance of k from that tail in standard deviations.
+func (d *Hypergeometric) CDF(k uint64) float64 {
+	x := float64(k)
+	r, b, n := float64(d.successes), float64(d.total-d.successes), float64(d.draws)
+	lower := true
+	if x*(r+b) > n*r { // sum the upper tail instead
+		r, b = b, r
+		x = n - x - 1
+		lower = false
+	}
+	lo := math.Max(0, n-b) // bottom of the support
+	var p float64
+	switch {
+	case x < lo:
+		p = 0
+	case x >= r || x >= n:
+		p = 1
+	default:
+		// P(X <= x) / P(X = x) = 1 + sum of P(X = i) / P(X = x) for i < x
+		sum, term := 0.0, 1.0
+		for i := x; i > lo && term >= specEps*sum; i-- {
+			term *= i * (b - n + i) / (n + 1 - i) / (r + 1 - i)
+			sum += term
+		}
+		p = dhyper(x, r, b, n) * (1 + sum)
+	}
+	if lower {
+		return math.Min(p, 1)
+	}
+	return math.Max(1-p, 0)
+}
+
+// Quantile returns the smallest k such that CDF(k) >= p. It panics if p is not in [0, 1].
+func (d *Hypergeometric) Quantile(p float64) uint64 {
+	checkQuantile(p)
+	max := d.draws
+	if d.successes < max {
+		max = d.successes
+	}
+	return searchQuantile(d.CDF, p, max)
+}
+
+// Mean returns the expected value.
+func (d *Hypergeometric) Mean() float64 {
+	if d.total == 0 {
+		return 0
+	}
+	return float64(d.draws) * float64(d.successes) / float64(d.total)
+}
+
+// Variance returns the variance.
+func (d *Hypergeometric) Variance() float64 {
+	if d.total <= 1 {
+		return 0
+	}
+	n, N := float64(d.draws), float64(d.total)
+	p := float64(d.successes) / N
+	return n * p * (1 - p) * (N - n) / (N - 1)
+}
+
+// Multinomial is the multinomial distribution: the numbers of outcomes of each category
+// in n independent trials with the given category probabilities.
+type Multinomial struct {
+	n     uint64
+	p     []float64 // normalized probabilities
+	cond  []float64 // probability of category i given that it is not one of the previous ones
+	final int       // last category with positive probability
+}
+
+// NewMultinomial returns the multinomial distribution of n trials with category probabilities
+// proportional to weights. It returns an error if weights is empty, contains negative, infinite
+// or NaN numbers, or does not contain positive numbers.
+func NewMultinomial(n uint64, weights []float64) (*Multinomial, error) {
+	if len(weights) == 0 {
+		return nil, errors.New("rand: no multinomial distribution weights")
+	}
+	final := -1
+	for i, w := range weights {
+		if !isFinite(w) || w < 0 {
+			return nil, fmt.Errorf("rand: invalid multinomial distribution weight %v at index %v", w, i)
+		}
+		if w > 0 {
+			final = i
+		}
+	}
+	if final < 0 {
+		return nil, errors.New("rand: no positive multinomial distribution weights")
+	}
+	d := &Multinomial{n: n, p: make([]float64, len(weights)), cond: make([]float64, len(weights)), final: final}
+	tail := 0.0
+	for i := len(weights) - 1; i >= 0; i-- {
+		tail += weights[i]
+		if tail > 0 {
+			d.cond[i] = math.Min(weights[i]/tail, 1)
+		}
+	}
+	for i, w := range weights {
+		d.p[i] = w / tail
+	}
+	return d, nil
+}
+
+// Len returns the number of categories.
+func (d *Multinomial) Len() int {
+	return len(d.p)
+}
+
+// Mean returns the expected numbers of outcomes of each category.
+func (d *Multinomial) Mean() []float64 {
+	m := make([]float64, len(d.p))
+	for i, p := range d.p {
+		m[i] = float64(d.n) * p
+	}
+	return m
+}
+
+// Sample fills dst with multinomially distributed pseudo-random numbers of outcomes of each category.
+// It panics if len(dst) != [Multinomial.Len].
+//
+// Sample draws the number of outcomes of each category in turn, from the binomial distribution
+// conditional on the previous ones, so it needs O(Len) time regardless of n.
+func (d *Multinomial) Sample(r *Rand, dst []uint64) {
+	if len(dst) != len(d.p) {
+		panic("invalid argument to Sample")
+	}
+	rem := d.n
+	var b Binomial
+	for i := range dst {
+		switch {
+		case i == d.final:
+			dst[i] = rem
+		case rem == 0 || i > d.final:
+			dst[i] = 0
+		default:
+			b.init(rem, d.cond[i])
+			dst[i] = b.Sample(r)
+		}
+		rem -= dst[i]
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+func TestGeometric(t *testing.T) {
+	for _, p := range []float64{1, 0.5, 0.9, 0.1, 0.01} {
+		d, err := rand.NewGeometric(p)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkDiscrete(t, "geometric", d)
+	}
+	for _, p := range []float64{1e-6, 1e-15} {
+		d, _ := rand.NewGeometric(p)
+		checkDiscreteWide(t, "geometric", d)
+	}
+	for _, p := range []float64{0, -0.1, 1.1, math.NaN()} {
+		if _, err := rand.NewGeometric(p); err == nil {
+			t.Errorf("got no error for p %v", p)
+		}
+	}
+}
+
+func TestGeometric_Tiny(t *testing.T) {
+	d, _ := rand.NewGeometric(0x1p-1000)
+	if q := d.Quantile(0.5); q != math.MaxUint64 {
+		t.Errorf("got median %v instead of %v", q, uint64(math.MaxUint64))
+	}
+	r := rand.New(1)
+	for i := 0; i < small; i++ {
+		if k := d.Sample(r); k < 1<<60 {
+			t.Fatalf("got sample %v", k)
+		}
+	}
+}
+
+func TestNegativeBinomial(t *testing.T) {
+	for _, c := range []struct{ r, p float64 }{{1, 0.5}, {3, 0.3}, {0.5, 0.1}, {10, 0.9}, {2.5, 1}} {
+		d, err := rand.NewNegativeBinomial(c.r, c.p)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkDiscrete(t, "negative binomial", d)
+	}
+	for _, c := range []struct{ r, p float64 }{{1e3, 0.01}, {0.1, 1e-5}} {
+		d, _ := rand.NewNegativeBinomial(c.r, c.p)
+		checkDiscreteWide(t, "negative binomial", d)
+	}
+	for _, c := range []struct{ r, p float64 }{{0, 0.5}, {1, 0}, {1, 1.1}, {math.Inf(1), 0.5}, {1, math.NaN()}} {
+		if _, err := rand.NewNegativeBinomial(c.r, c.p); err == nil {
+			t.Errorf("got no error for r %v, p %v", c.r, c.p)
+		}
+	}
+}
+
+func TestHypergeometric(t *testing.T) {
+	for _, c := range []struct{ total, successes, draws uint64 }{
+		{0, 0, 0}, {10, 0, 5}, {10, 10, 5}, {10, 5, 10}, {20, 7, 12}, {100, 30, 10},
+		{1000, 600, 300}, {1000, 600, 800}, {5000, 100, 4000}, {10000, 5000, 5000},
+	} {
+		d, err := rand.NewHypergeometric(c.total, c.successes, c.draws)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkDiscrete(t, "hypergeometric", d)
+	}
+	for _, c := range []struct{ total, successes, draws uint64 }{{1e6, 3e5, 5e5}, {1e9, 1e8, 7e8}} {
+		d, _ := rand.NewHypergeometric(c.total, c.successes, c.draws)
+		checkDiscreteWide(t, "hypergeometric", d)
+	}
+	for _, c := range []struct{ total, successes, draws uint64 }{{10, 11, 5}, {10, 5, 11}} {
+		if _, err := rand.NewHypergeometric(c.total, c.successes, c.draws); err == nil {
+			t.Errorf("got no error for %v", c)
+		}
+	}
+}
+
+func TestHypergeometric_SupportMinimum(t *testing.T) {
+	// the support starts at draws - failures = 5e17, and summing the PMF ratios must stop there
+	const lo = 5e17
+	d, _ := rand.NewHypergeometric(2e18, 15e17, 1e18)
+	if p := d.CDF(lo - 1); p != 0 {
+		t.Errorf("got CDF %v below the support", p)
+	}
+	if p, q := d.CDF(lo), d.PMF(lo); p != q {
+		t.Errorf("got CDF %v instead of PMF %v at the bottom of the support", p, q)
+	}
+	// the binary search starts at the middle of [0, 1e18], which is the bottom of the support
+	if k := d.Quantile(0); k != 0 {
+		t.Errorf("got quantile %v instead of 0", k)
+	}
+	d, _ = rand.NewHypergeometric(20, 15, 10)
+	if p, q := d.CDF(5), d.PMF(5); p != q || p == 0 {
+		t.Errorf("got CDF %v instead of PMF %v at the bottom of the support", p, q)
+	}
+}
+
+func TestMultinomial(t *testing.T) {
+	weights := []float64{0, 1, 2, 0, 7, 0}
+	const n = 1000
+	d, err := rand.NewMultinomial(n, weights)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	r := rand.New(1)
+	dst := make([]uint64, d.Len())
+	var sum [6]float64
+	for i := 0; i < small; i++ {
+		d.Sample(r, dst)
+		total := uint64(0)
+		for j, c := range dst {
+			if weights[j] == 0 && c != 0 {
+				t.Fatalf("got %v outcomes of category %v with zero weight", c, j)
+			}
+			total += c
+			sum[j] += float64(c)
+		}
+		if total != n {
+			t.Fatalf("got %v outcomes instead of %v", total, n)
+		}
+	}
+	// each count is binomially distributed
+	for j, m := range d.Mean() {
+		p := m / n
+		if mean := sum[j] / small; math.Abs(mean-m) > 6*math.Sqrt(n*p*(1-p)/small) {
+			t.Errorf("got mean %v for category %v instead of %v", mean, j, m)
+		}
+	}
+
+	for _, w := range [][]float64{nil, {0, 0}, {1, -1}, {1, math.NaN()}, {math.Inf(1)}} {
+		if _, err := rand.NewMultinomial(n, w); err == nil {
+			t.Errorf("got no error for %v", w)
+		}
+	}
+}
+
+func BenchmarkHypergeometric(b *testing.B) {
+	for _, total := range []uint64{100, 1e9} {
+		d, _ := rand.NewHypergeometric(total, total/3, total/2)
+		b.Run(fmt.Sprint(total), func(b *testing.B) {
+			r := rand.New(1)
+			for i := 0; i < b.N; i++ {
+				sinkUint64 = d.Sample(r)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	_ Continuous = (*Gamma)(nil)
+	_ Continuous = (*Beta)(nil)
+	_ Continuous = (*ChiSquared)(nil)
+)
+
+// Gamma is the gamma distribution with shape k and scale θ, with density proportional to x^(k-1) * e^(-x/θ).
+type Gamma struct {
+	shape float64
+	scale float64
+}
+
+// NewGamma returns the gamma distribution with the given shape and scale.
+// It returns an error unless shape and scale are finite and positive.
+func NewGamma(shape float64, scale float64) (*Gamma, error) {
+	if !isFinite(shape) || shape <= 0 || !isFinite(scale) || scale <= 0 {
+		return nil, fmt.Errorf("rand: invalid gamma distribution parameters shape=%v scale=%v", shape, scale)
+	}
+	return &Gamma{shape: shape, scale: scale}, nil
+}
+
+// Sample returns a gamma distributed pseudo-random number.
+//
+// Sample uses the method of Marsaglia and Tsang, based on [Rand.NormFloat64];
+// for shape < 1, the result for shape+1 is multiplied by U^(1/shape).
+func (g *Gamma) Sample(r *Rand) float64 {
+	return gammaSample(r, g.shape) * g.scale
+}
+
+// PDF returns the probability density function at x.
+func (g *Gamma) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x == 0 {
+		switch {
+		case g.shape < 1:
+			return math.Inf(1)
+		case g.shape == 1:
+			return 1 / g.scale
+		default:
+			return 0
+		}
+	}
+	y := x / g.scale
+	return math.Exp((g.shape-1)*math.Log(y)-y-lgamma(g.shape)) / g.scale
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (g *Gamma) CDF(x float64) float64 {
+	return gammaIncP(g.shape, x/g.scale)
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (g *Gamma) Quantile(p float64) float64 {
+	checkQuantile(p)
+	if p == 0 {
+		return 0
+	}
+	if p == 1 {
+		return math.Inf(1)
+	}
+	return invertCDF(g.CDF, p, 0, math.Inf(1), g.Mean())
+}
+
+// Mean returns the expected value.
+func (g *Gamma) Mean() float64 {
+	return g.shape * g.scale
+}
+
+// Variance returns the variance.
+func (g *Gamma) Variance() float64 {
+	return g.shape * g.scale * g.scale
+}
+
+// gammaSample returns a pseudo-random number with the gamma distribution with shape a and scale 1.
+func gammaSample(r *Rand, a float64) float64 {
+	if a < 1 {
+		// U^(1/a) = e^(-E/a) for a standard exponential E
+		return gammaSample(r, a+1) * math.Exp(-r.ExpFloat64()/a)
+	}
+	d := a - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := r.Float64Open()
+		x2 := x * x
+		if u < 1-0.0331*x2*x2 {
+			return d * v
+		}
+		if math.Log(u) < x2/2+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
+
+// logGammaSample returns the logarithm of gammaSample(r, a), without underflow for small a.
+func logGammaSample(r *Rand, a float64) float64 {
+	if a < 1 {
+		return math.Log(gammaSample(r, a+1)) - r.ExpFloat64()/a
+	}
+	return math.Log(gammaSample(r, a))
+}
+
+// Beta is the beta distribution on [0, 1], with density proportional to x^(α-1) * (1-x)^(β-1).
+type Beta struct {
+	alpha float64
+	beta  float64
+}
+
+// NewBeta returns the beta distribution with the given shape parameters α and β.
+// It returns an error unless alpha and beta are finite and positive.
+func NewBeta(alpha float64, beta float64) (*Beta, error) {
+	if !isFinite(alpha) || alpha <= 0 || !isFinite(beta) || beta <= 0 {
+		return nil, fmt.Errorf("rand: invalid beta distribution parameters alpha=%v beta=%v", alpha, beta)
+	}
+	return &Beta{alpha: alpha, beta: beta}, nil
+}
+
+// Sample returns a beta distributed pseudo-random number.
+//
+// When both shape parameters are greater than 1, Sample uses Cheng's algorithm BB.
+// Otherwise, it uses the ratio X/(X+Y) of gamma distributed X and Y, computed in log space
+// so that it does not degenerate to 0/0 for tiny parameters.
+func (b *Beta) Sample(r *Rand) float64 {
+	if b.alpha > 1 && b.beta > 1 {
+		return betaCheng(r, b.alpha, b.beta)
+	}
+	lx := logGammaSample(r, b.alpha)
+	ly := logGammaSample(r, b.beta)
+	// X/(X+Y) = 1/(1+e^(ly-lx))
+	if d := ly - lx; d > 0 {
+		e := math.Exp(-d)
+		return e / (1 + e)
+	} else {
+		return 1 / (1 + math.Exp(d))
+	}
+}
+
+// betaCheng implements algorithm BB by R. C. H. Cheng, "Generating beta variates with nonintegral shape parameters" (1978).
+func betaCheng(r *Rand, a float64, b float64) float64 {
+	a0, b0 := math.Min(a, b), math.Max(a, b)
+	alpha := a0 + b0
+	beta := math.Sqrt((alpha - 2) / (2*a0*b0 - alpha))
+	gamma := a0 + 1/beta
+	var w float64
+	for {
+		u1 := r.Float64Open()
+		u2 := r.Float64Open()
+		v := beta * math.Log(u1/(1-u1))
+		w = a0 * math.Exp(v)
+		z := u1 * u1 * u2
+		rr := gamma*v - math.Ln2*2
+		s := a0 + rr - w
+		if s+1+math.Log(5) >= 5*z {
+			break
+		}
+		t := math.Log(z)
+		if s > t {
+			break
+		}
+		if rr+alpha*math.Log(alpha/(b0+w)) >= t {
+			break
+		}
+	}
+	if math.IsInf(w, 1) {
+		w = math.MaxFloat64
+	}
+	if a == a0 {
+		return w / (b0 + w)
+	}
+	return b0 / (b0 + w)
+}
+
+// PDF returns the probability density function at x.
+func (b *Beta) PDF(x float64) float64 {
+	if x < 0 || x > 1 {
+		return 0
+	}
+	return math.Exp((b.alpha-1)*math.Log(x) + (b.beta-1)*math.Log1p(-x) - lbeta(b.alpha, b.beta))
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (b *Beta) CDF(x float64) float64 {
+	return betaInc(b.alpha, b.beta, x)
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (b *Beta) Quantile(p float64) float64 {
+	checkQuantile(p)
+	if p == 0 {
+		return 0
+	}
+	if p == 1 {
+		return 1
+	}
+	return invertCDF(b.CDF, p, 0, 1, b.Mean())
+}
+
+// Mean returns the expected value.
+func (b *Beta) Mean() float64 {
+	return b.alpha / (b.alpha + b.beta)
+}
+
+// Variance returns the variance.
+func (b *Beta) Variance() float64 {
+	s := b.alpha + b.beta
+	return b.alpha * b.beta / (s * s * (s + 1))
+}
+
+// ChiSquared is the chi-squared distribution with k degrees of freedom,
+// which is the gamma distribution with shape k/2 and scale 2.
+type ChiSquared struct {
+	g Gamma
+}
+
+// NewChiSquared returns the chi-squared distribution with k degrees of freedom.
+// It returns an error unless k is finite and positive.
+func NewChiSquared(k float64) (*ChiSquared, error) {
+	if !isFinite(k) || k <= 0 {
+		return nil, fmt.Errorf("rand: invalid chi-squared distribution degrees of freedom %v", k)
+	}
+	return &ChiSquared{g: Gamma{shape: k / 2, scale: 2}}, nil
+}
+
+// Sample returns a chi-squared distributed pseudo-random number.
+func (c *ChiSquared) Sample(r *Rand) float64 {
+	return c.g.Sample(r)
+}
+
+// PDF returns the probability density function at x.
+func (c *ChiSquared) PDF(x float64) float64 {
+	return c.g.PDF(x)
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (c *ChiSquared) CDF(x float64) float64 {
+	return c.g.CDF(x)
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (c *ChiSquared) Quantile(p float64) float64 {
+	return c.g.Quantile(p)
+}
+
+// Mean returns the expected value.
+func (c *ChiSquared) Mean() float64 {
+	return c.g.Mean()
+}
+
+// Variance returns the variance.
+func (c *ChiSquared) Variance() float64 {
+	return c.g.Variance()
+}
+
+// Dirichlet is the Dirichlet distribution of probability vectors: non-negative numbers that sum to 1.
+type Dirichlet struct {
+	alpha []float64
+	sum   float64
+}
+
+// NewDirichlet returns the Dirichlet distribution with the given concentration parameters.
+// It returns an error if alpha is empty, or unless all of its elements are finite and positive.
+func NewDirichlet(alpha []float64) (*Dirichlet, error) {
+	if len(alpha) == 0 {
+		return nil, errors.New("rand: no Dirichlet concentration parameters")
+	}
+	sum := 0.0
+	for i, a := range alpha {
+		if !isFinite(a) || a <= 0 {
+			return nil, fmt.Errorf("rand: invalid Dirichlet concentration parameter %v at index %v", a, i)
+		}
+		sum += a
+	}
+	return &Dirichlet{alpha: append([]float64(nil), alpha...), sum: sum}, nil
+}
+
+// Len returns the number of elements of the probability vectors.
+func (d *Dirichlet) Len() int {
+	return len(d.alpha)
+}
+
+// Mean returns the expected probability vector.
+func (d *Dirichlet) Mean() []float64 {
+	m := make([]float64, len(d.alpha))
+	for i, a := range d.alpha {
+		m[i] = a / d.sum
+	}
+	return m
+}
+
+// Sample fills dst with a Dirichlet distributed pseudo-random probability vector.
+// It panics if len(dst) != [Dirichlet.Len].
+//
+// Sample normalizes gamma distributed numbers, computed in log space
+// so that the result is well-defined even for tiny concentration parameters.
+func (d *Dirichlet) Sample(r *Rand, dst []float64) {
+	if len(dst) != len(d.alpha) {
+		panic("invalid argument to Sample")
+	}
+	max := math.Inf(-1)
+	for i, a := range d.alpha {
+		dst[i] = logGammaSample(r, a)
+		if dst[i] > max {
+			max = dst[i]
+		}
+	}
+	sum := 0.0
+	for i, l := range dst {
+		dst[i] = math.Exp(l - max)
+		sum += dst[i]
+	}
+	for i := range dst {
+		dst[i] /= sum
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+func TestGamma(t *testing.T) {
+	for _, c := range []struct{ shape, scale float64 }{{1, 1}, {0.1, 1}, {0.5, 3}, {2.5, 0.01}, {100, 1}} {
+		d, err := rand.NewGamma(c.shape, c.scale)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "gamma", d)
+	}
+	for _, c := range []struct{ shape, scale float64 }{{0, 1}, {1, 0}, {-1, 1}, {math.NaN(), 1}, {1, math.Inf(1)}} {
+		if _, err := rand.NewGamma(c.shape, c.scale); err == nil {
+			t.Errorf("got no error for shape %v, scale %v", c.shape, c.scale)
+		}
+	}
+}
+
+func TestBeta(t *testing.T) {
+	for _, c := range []struct{ alpha, beta float64 }{{1, 1}, {0.5, 0.5}, {2, 5}, {30, 1.5}, {0.2, 3}} {
+		d, err := rand.NewBeta(c.alpha, c.beta)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "beta", d)
+	}
+	for _, c := range []struct{ alpha, beta float64 }{{0, 1}, {1, -1}, {math.NaN(), 1}, {math.Inf(1), 1}} {
+		if _, err := rand.NewBeta(c.alpha, c.beta); err == nil {
+			t.Errorf("got no error for alpha %v, beta %v", c.alpha, c.beta)
+		}
+	}
+}
+
+func TestBeta_Tiny(t *testing.T) {
+	d, _ := rand.NewBeta(1e-3, 1e-3)
+	r := rand.New(1)
+	for i := 0; i < small; i++ {
+		if x := d.Sample(r); !(x >= 0 && x <= 1) {
+			t.Fatalf("got %v outside of [0, 1]", x)
+		}
+	}
+}
+
+func TestChiSquared(t *testing.T) {
+	for _, k := range []float64{1, 2, 3.5, 50} {
+		d, err := rand.NewChiSquared(k)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "chi-squared", d)
+	}
+	for _, k := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := rand.NewChiSquared(k); err == nil {
+			t.Errorf("got no error for k %v", k)
+		}
+	}
+}
+
+func TestDirichlet(t *testing.T) {
+	alpha := []float64{0.5, 1, 3, 1e-3}
+	d, err := rand.NewDirichlet(alpha)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	sum := 0.0
+	for _, a := range alpha {
+		sum += a
+	}
+
+	// each element of a Dirichlet vector is beta distributed
+	r := rand.New(1)
+	x := make([]float64, d.Len())
+	samples := make([][]float64, len(alpha))
+	for i := 0; i < distSamples; i++ {
+		d.Sample(r, x)
+		s := 0.0
+		for j, v := range x {
+			if !(v >= 0 && v <= 1) {
+				t.Fatalf("got element %v outside of [0, 1]", v)
+			}
+			s += v
+			samples[j] = append(samples[j], v)
+		}
+		if math.Abs(s-1) > 1e-12 {
+			t.Fatalf("got elements that sum to %v", s)
+		}
+	}
+	for j, a := range alpha[:3] {
+		b, _ := rand.NewBeta(a, sum-a)
+		counts := make([]int, 16)
+		expected := make([]float64, 16)
+		for _, v := range samples[j] {
+			k := int(b.CDF(v) * 16)
+			if k == 16 {
+				k--
+			}
+			counts[k]++
+		}
+		for i := range expected {
+			expected[i] = distSamples / 16
+		}
+		checkChiSquared(t, counts, expected)
+	}
+
+	for _, a := range [][]float64{nil, {1, 0}, {1, math.NaN()}, {-1}} {
+		if _, err := rand.NewDirichlet(a); err == nil {
+			t.Errorf("got no error for %v", a)
+		}
+	}
+}
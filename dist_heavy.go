@@ -0,0 +1,485 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"fmt"
+	"math"
+)
+
+var (
+	_ Continuous = (*Pareto)(nil)
+	_ Continuous = (*BoundedPareto)(nil)
+	_ Continuous = (*LogNormal)(nil)
+	_ Continuous = (*Weibull)(nil)
+	_ Continuous = (*Cauchy)(nil)
+	_ Continuous = (*StudentT)(nil)
+	_ Continuous = (*Levy)(nil)
+)
+
+// float64FullOpen returns [Rand.Float64Full] conditioned on being non-zero,
+// which is the uniform number in (0.0, 1.0) to use for inverse transform sampling of heavy tails.
+func float64FullOpen(r *Rand) float64 {
+	for {
+		if u := r.Float64Full(); u > 0 {
+			return u
+		}
+	}
+}
+
+// Pareto is the Pareto distribution with scale (minimum) xm and shape (tail index) α,
+// with the survival function (xm/x)^α for x >= xm.
+type Pareto struct {
+	xm    float64
+	alpha float64
+}
+
+// NewPareto returns the Pareto distribution with the given scale and shape.
+// It returns an error unless xm and alpha are finite and positive.
+func NewPareto(xm float64, alpha float64) (*Pareto, error) {
+	if !isFinite(xm) || xm <= 0 || !isFinite(alpha) || alpha <= 0 {
+		return nil, fmt.Errorf("rand: invalid Pareto distribution parameters xm=%v alpha=%v", xm, alpha)
+	}
+	return &Pareto{xm: xm, alpha: alpha}, nil
+}
+
+// Sample returns a Pareto distributed pseudo-random number.
+// It uses inverse transform sampling on [Rand.Float64Full], so the tail is accurate far beyond 2^53*xm.
+func (d *Pareto) Sample(r *Rand) float64 {
+	return d.xm * math.Exp(-math.Log(float64FullOpen(r))/d.alpha)
+}
+
+// PDF returns the probability density function at x.
+func (d *Pareto) PDF(x float64) float64 {
+	if x < d.xm {
+		return 0
+	}
+	return d.alpha / x * math.Exp(d.alpha*math.Log(d.xm/x))
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (d *Pareto) CDF(x float64) float64 {
+	if x <= d.xm {
+		return 0
+	}
+	return -math.Expm1(d.alpha * math.Log(d.xm/x))
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (d *Pareto) Quantile(p float64) float64 {
+	checkQuantile(p)
+	return d.xm * math.Exp(-math.Log1p(-p)/d.alpha)
+}
+
+// Mean returns the expected value, which is infinite for α <= 1.
+func (d *Pareto) Mean() float64 {
+	if d.alpha <= 1 {
+		return math.Inf(1)
+	}
+	return d.alpha * d.xm / (d.alpha - 1)
+}
+
+// Variance returns the variance, which is infinite for α <= 2.
+func (d *Pareto) Variance() float64 {
+	if d.alpha <= 2 {
+		return math.Inf(1)
+	}
+	a1 := d.alpha - 1
+	return d.xm * d.xm * d.alpha / (a1 * a1 * (d.alpha - 2))
+}
+
+// BoundedPareto is the Pareto distribution with shape α, truncated to the interval [lo, hi].
+type BoundedPareto struct {
+	lo    float64
+	hi    float64
+	alpha float64
+	norm  float64 // (lo/hi)^α - 1
+}
+
+// NewBoundedPareto returns the Pareto distribution with shape alpha truncated to [lo, hi].
+// It returns an error unless lo, hi and alpha are finite and positive, and lo < hi.
+func NewBoundedPareto(lo float64, hi float64, alpha float64) (*BoundedPareto, error) {
+	if !isFinite(lo) || lo <= 0 || !isFinite(hi) || hi <= lo || !isFinite(alpha) || alpha <= 0 {
+		return nil, fmt.Errorf("rand: invalid bounded Pareto distribution parameters lo=%v hi=%v alpha=%v", lo, hi, alpha)
+	}
+	return &BoundedPareto{lo: lo, hi: hi, alpha: alpha, norm: math.Expm1(alpha * math.Log(lo/hi))}, nil
+}
+
+// Sample returns a bounded Pareto distributed pseudo-random number.
+func (d *BoundedPareto) Sample(r *Rand) float64 {
+	return d.quantile(r.Float64Full())
+}
+
+// PDF returns the probability density function at x.
+func (d *BoundedPareto) PDF(x float64) float64 {
+	if x < d.lo || x > d.hi {
+		return 0
+	}
+	return -d.alpha / x * math.Exp(d.alpha*math.Log(d.lo/x)) / d.norm
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (d *BoundedPareto) CDF(x float64) float64 {
+	if x <= d.lo {
+		return 0
+	}
+	if x >= d.hi {
+		return 1
+	}
+	return math.Expm1(d.alpha*math.Log(d.lo/x)) / d.norm
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (d *BoundedPareto) Quantile(p float64) float64 {
+	checkQuantile(p)
+	return d.quantile(p)
+}
+
+func (d *BoundedPareto) quantile(p float64) float64 {
+	x := d.lo * math.Exp(-math.Log1p(p*d.norm)/d.alpha)
+	return math.Min(math.Max(x, d.lo), d.hi)
+}
+
+// Mean returns the expected value.
+func (d *BoundedPareto) Mean() float64 {
+	return d.moment(1)
+}
+
+// Variance returns the variance.
+func (d *BoundedPareto) Variance() float64 {
+	m := d.moment(1)
+	return d.moment(2) - m*m
+}
+
+// moment returns E[X^n].
+func (d *BoundedPareto) moment(n float64) float64 {
+	l := math.Log(d.lo / d.hi)
+	c := math.Pow(d.lo, n) / -d.norm
+	if d.alpha == n {
+		return c * d.alpha * -l
+	}
+	return c * d.alpha / (d.alpha - n) * -math.Expm1((d.alpha-n)*l)
+}
+
+// LogNormal is the log-normal distribution: the distribution of e^X for normally distributed X.
+type LogNormal struct {
+	mu    float64
+	sigma float64
+}
+
+// NewLogNormal returns the log-normal distribution whose logarithm has mean mu and standard deviation sigma.
+// It returns an error unless mu is finite, and sigma is finite and positive.
+func NewLogNormal(mu float64, sigma float64) (*LogNormal, error) {
+	if !isFinite(mu) || !isFinite(sigma) || sigma <= 0 {
+		return nil, fmt.Errorf("rand: invalid log-normal distribution parameters mu=%v sigma=%v", mu, sigma)
+	}
+	return &LogNormal{mu: mu, sigma: sigma}, nil
+}
+
+// Sample returns a log-normally distributed pseudo-random number, using [Rand.NormFloat64].
+func (d *LogNormal) Sample(r *Rand) float64 {
+	return math.Exp(d.mu + d.sigma*r.NormFloat64())
+}
+
+// PDF returns the probability density function at x.
+func (d *LogNormal) PDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	z := (math.Log(x) - d.mu) / d.sigma
+	return math.Exp(-z*z/2) / (x * d.sigma * math.Sqrt(2*math.Pi))
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (d *LogNormal) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return math.Erfc(-(math.Log(x)-d.mu)/(d.sigma*math.Sqrt2)) / 2
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (d *LogNormal) Quantile(p float64) float64 {
+	checkQuantile(p)
+	return math.Exp(d.mu - d.sigma*math.Sqrt2*math.Erfcinv(2*p))
+}
+
+// Mean returns the expected value.
+func (d *LogNormal) Mean() float64 {
+	return math.Exp(d.mu + d.sigma*d.sigma/2)
+}
+
+// Variance returns the variance.
+func (d *LogNormal) Variance() float64 {
+	s2 := d.sigma * d.sigma
+	return math.Expm1(s2) * math.Exp(2*d.mu+s2)
+}
+
+// Weibull is the Weibull distribution with scale λ and shape k, with the survival function e^(-(x/λ)^k) for x >= 0.
+type Weibull struct {
+	scale float64
+	shape float64
+}
+
+// NewWeibull returns the Weibull distribution with the given scale and shape.
+// It returns an error unless scale and shape are finite and positive.
+func NewWeibull(scale float64, shape float64) (*Weibull, error) {
+	if !isFinite(scale) || scale <= 0 || !isFinite(shape) || shape <= 0 {
+		return nil, fmt.Errorf("rand: invalid Weibull distribution parameters scale=%v shape=%v", scale, shape)
+	}
+	return &Weibull{scale: scale, shape: shape}, nil
+}
+
+// Sample returns a Weibull distributed pseudo-random number, as a power of [Rand.ExpFloat64].
+func (d *Weibull) Sample(r *Rand) float64 {
+	return d.scale * math.Pow(r.ExpFloat64(), 1/d.shape)
+}
+
+// PDF returns the probability density function at x.
+func (d *Weibull) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x == 0 {
+		switch {
+		case d.shape < 1:
+			return math.Inf(1)
+		case d.shape == 1:
+			return 1 / d.scale
+		default:
+			return 0
+		}
+	}
+	y := x / d.scale
+	yk := math.Pow(y, d.shape)
+	return d.shape / x * yk * math.Exp(-yk)
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (d *Weibull) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return -math.Expm1(-math.Pow(x/d.scale, d.shape))
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (d *Weibull) Quantile(p float64) float64 {
+	checkQuantile(p)
+	return d.scale * math.Pow(-math.Log1p(-p), 1/d.shape)
+}
+
+// Mean returns the expected value.
+func (d *Weibull) Mean() float64 {
+	return d.scale * math.Gamma(1+1/d.shape)
+}
+
+// Variance returns the variance.
+func (d *Weibull) Variance() float64 {
+	g1 := math.Gamma(1 + 1/d.shape)
+	return d.scale * d.scale * (math.Gamma(1+2/d.shape) - g1*g1)
+}
+
+// Cauchy is the Cauchy (Lorentz) distribution with location x0 and scale γ.
+type Cauchy struct {
+	loc   float64
+	scale float64
+}
+
+// NewCauchy returns the Cauchy distribution with the given location and scale.
+// It returns an error unless loc is finite, and scale is finite and positive.
+func NewCauchy(loc float64, scale float64) (*Cauchy, error) {
+	if !isFinite(loc) || !isFinite(scale) || scale <= 0 {
+		return nil, fmt.Errorf("rand: invalid Cauchy distribution parameters loc=%v scale=%v", loc, scale)
+	}
+	return &Cauchy{loc: loc, scale: scale}, nil
+}
+
+// Sample returns a Cauchy distributed pseudo-random number.
+// It uses inverse transform sampling on [Rand.Float64Full] for each half of the distribution,
+// so both tails are accurate.
+func (d *Cauchy) Sample(r *Rand) float64 {
+	x := d.scale / math.Tan(math.Pi/2*float64FullOpen(r))
+	if r.Bool() {
+		x = -x
+	}
+	return d.loc + x
+}
+
+// PDF returns the probability density function at x.
+func (d *Cauchy) PDF(x float64) float64 {
+	z := (x - d.loc) / d.scale
+	return 1 / (math.Pi * d.scale * (1 + z*z))
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (d *Cauchy) CDF(x float64) float64 {
+	z := (x - d.loc) / d.scale
+	if z < 0 {
+		return math.Atan(-1/z) / math.Pi
+	}
+	return 0.5 + math.Atan(z)/math.Pi
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (d *Cauchy) Quantile(p float64) float64 {
+	checkQuantile(p)
+	switch {
+	case p < 0.5:
+		return d.loc - d.scale/math.Tan(math.Pi*p)
+	case p > 0.5:
+		return d.loc + d.scale/math.Tan(math.Pi*(1-p))
+	default:
+		return d.loc
+	}
+}
+
+// Mean returns NaN, as the Cauchy distribution has no expected value.
+func (d *Cauchy) Mean() float64 {
+	return math.NaN()
+}
+
+// Variance returns NaN, as the Cauchy distribution has no variance.
+func (d *Cauchy) Variance() float64 {
+	return math.NaN()
+}
+
+// StudentT is Student's t-distribution with ν degrees of freedom.
+type StudentT struct {
+	nu   float64
+	norm float64 // log of the PDF at 0
+}
+
+// NewStudentT returns Student's t-distribution with nu degrees of freedom.
+// It returns an error unless nu is finite and positive.
+func NewStudentT(nu float64) (*StudentT, error) {
+	if !isFinite(nu) || nu <= 0 {
+		return nil, fmt.Errorf("rand: invalid Student's t-distribution degrees of freedom %v", nu)
+	}
+	return &StudentT{nu: nu, norm: -lbeta(nu/2, 0.5) - math.Log(nu)/2}, nil
+}
+
+// Sample returns a t-distributed pseudo-random number, as the ratio of [Rand.NormFloat64]
+// and the square root of an independent chi-squared distributed number divided by ν.
+func (d *StudentT) Sample(r *Rand) float64 {
+	z := r.NormFloat64()
+	v := 2 * gammaSample(r, d.nu/2)
+	return z / math.Sqrt(v/d.nu)
+}
+
+// PDF returns the probability density function at x.
+func (d *StudentT) PDF(x float64) float64 {
+	return math.Exp(d.norm - (d.nu+1)/2*math.Log1p(x*x/d.nu))
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (d *StudentT) CDF(x float64) float64 {
+	if math.IsInf(x, 0) {
+		if x > 0 {
+			return 1
+		}
+		return 0
+	}
+	tail := betaInc(d.nu/2, 0.5, d.nu/(d.nu+x*x)) / 2 // P(T > |x|)
+	if x < 0 {
+		return tail
+	}
+	return 1 - tail
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (d *StudentT) Quantile(p float64) float64 {
+	checkQuantile(p)
+	switch {
+	case p == 0:
+		return math.Inf(-1)
+	case p == 1:
+		return math.Inf(1)
+	case p < 0.5:
+		return invertCDF(d.CDF, p, math.Inf(-1), 0, -1)
+	default:
+		return invertCDF(d.CDF, p, 0, math.Inf(1), 1)
+	}
+}
+
+// Mean returns the expected value, which is NaN for ν <= 1.
+func (d *StudentT) Mean() float64 {
+	if d.nu <= 1 {
+		return math.NaN()
+	}
+	return 0
+}
+
+// Variance returns the variance, which is infinite for 1 < ν <= 2 and NaN for ν <= 1.
+func (d *StudentT) Variance() float64 {
+	switch {
+	case d.nu <= 1:
+		return math.NaN()
+	case d.nu <= 2:
+		return math.Inf(1)
+	default:
+		return d.nu / (d.nu - 2)
+	}
+}
+
+// Levy is the Lévy distribution with location μ and scale c: the distribution of μ + c/Z^2 for standard normal Z.
+type Levy struct {
+	loc   float64
+	scale float64
+}
+
+// NewLevy returns the Lévy distribution with the given location and scale.
+// It returns an error unless loc is finite, and scale is finite and positive.
+func NewLevy(loc float64, scale float64) (*Levy, error) {
+	if !isFinite(loc) || !isFinite(scale) || scale <= 0 {
+		return nil, fmt.Errorf("rand: invalid Lévy distribution parameters loc=%v scale=%v", loc, scale)
+	}
+	return &Levy{loc: loc, scale: scale}, nil
+}
+
+// Sample returns a Lévy distributed pseudo-random number.
+// It uses inverse transform sampling on [Rand.Float64Full], so the tail is accurate.
+func (d *Levy) Sample(r *Rand) float64 {
+	// |Z| = sqrt(2)*erfinv(U) for uniform U
+	e := math.Erfinv(float64FullOpen(r))
+	return d.loc + d.scale/(2*e*e)
+}
+
+// PDF returns the probability density function at x.
+func (d *Levy) PDF(x float64) float64 {
+	y := x - d.loc
+	if y <= 0 {
+		return 0
+	}
+	return math.Sqrt(d.scale/(2*math.Pi)) * math.Exp(-d.scale/(2*y)) / (y * math.Sqrt(y))
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (d *Levy) CDF(x float64) float64 {
+	y := x - d.loc
+	if y <= 0 {
+		return 0
+	}
+	return math.Erfc(math.Sqrt(d.scale / (2 * y)))
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (d *Levy) Quantile(p float64) float64 {
+	checkQuantile(p)
+	e := math.Erfcinv(p)
+	return d.loc + d.scale/(2*e*e)
+}
+
+// Mean returns +Inf, as the Lévy distribution has no finite expected value.
+func (d *Levy) Mean() float64 {
+	return math.Inf(1)
+}
+
+// Variance returns +Inf, as the Lévy distribution has no finite variance.
+func (d *Levy) Variance() float64 {
+	return math.Inf(1)
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+func TestPareto(t *testing.T) {
+	for _, c := range []struct{ xm, alpha float64 }{{1, 1}, {0.01, 0.5}, {3, 2.5}, {1e3, 10}} {
+		d, err := rand.NewPareto(c.xm, c.alpha)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "Pareto", d)
+	}
+	for _, c := range []struct{ xm, alpha float64 }{{0, 1}, {1, 0}, {-1, 1}, {math.NaN(), 1}, {1, math.Inf(1)}} {
+		if _, err := rand.NewPareto(c.xm, c.alpha); err == nil {
+			t.Errorf("got no error for xm %v, alpha %v", c.xm, c.alpha)
+		}
+	}
+}
+
+func TestBoundedPareto(t *testing.T) {
+	for _, c := range []struct{ lo, hi, alpha float64 }{{1, 2, 1}, {1, 1e6, 0.5}, {0.1, 10, 2}, {5, 6, 30}} {
+		d, err := rand.NewBoundedPareto(c.lo, c.hi, c.alpha)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "bounded Pareto", d)
+	}
+	for _, c := range []struct{ lo, hi, alpha float64 }{{0, 1, 1}, {2, 1, 1}, {1, 1, 1}, {1, 2, 0}, {1, math.Inf(1), 1}} {
+		if _, err := rand.NewBoundedPareto(c.lo, c.hi, c.alpha); err == nil {
+			t.Errorf("got no error for lo %v, hi %v, alpha %v", c.lo, c.hi, c.alpha)
+		}
+	}
+}
+
+func TestLogNormal(t *testing.T) {
+	for _, c := range []struct{ mu, sigma float64 }{{0, 1}, {-3, 0.1}, {5, 2}} {
+		d, err := rand.NewLogNormal(c.mu, c.sigma)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "log-normal", d)
+	}
+	for _, c := range []struct{ mu, sigma float64 }{{0, 0}, {0, -1}, {math.NaN(), 1}, {0, math.Inf(1)}} {
+		if _, err := rand.NewLogNormal(c.mu, c.sigma); err == nil {
+			t.Errorf("got no error for mu %v, sigma %v", c.mu, c.sigma)
+		}
+	}
+}
+
+func TestWeibull(t *testing.T) {
+	for _, c := range []struct{ scale, shape float64 }{{1, 1}, {2, 0.5}, {0.1, 3}, {100, 10}} {
+		d, err := rand.NewWeibull(c.scale, c.shape)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "Weibull", d)
+	}
+	for _, c := range []struct{ scale, shape float64 }{{0, 1}, {1, 0}, {math.NaN(), 1}, {1, math.Inf(1)}} {
+		if _, err := rand.NewWeibull(c.scale, c.shape); err == nil {
+			t.Errorf("got no error for scale %v, shape %v", c.scale, c.shape)
+		}
+	}
+}
+
+func TestCauchy(t *testing.T) {
+	for _, c := range []struct{ loc, scale float64 }{{0, 1}, {-5, 0.01}, {1e3, 10}} {
+		d, err := rand.NewCauchy(c.loc, c.scale)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "Cauchy", d)
+	}
+	for _, c := range []struct{ loc, scale float64 }{{0, 0}, {0, -1}, {math.NaN(), 1}, {0, math.Inf(1)}} {
+		if _, err := rand.NewCauchy(c.loc, c.scale); err == nil {
+			t.Errorf("got no error for loc %v, scale %v", c.loc, c.scale)
+		}
+	}
+}
+
+func TestStudentT(t *testing.T) {
+	for _, nu := range []float64{1, 0.5, 2, 3.5, 30} {
+		d, err := rand.NewStudentT(nu)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "Student's t", d)
+	}
+	for _, nu := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := rand.NewStudentT(nu); err == nil {
+			t.Errorf("got no error for nu %v", nu)
+		}
+	}
+}
+
+func TestStudentT_Cauchy(t *testing.T) {
+	// Student's t-distribution with 1 degree of freedom is the standard Cauchy distribution
+	s, _ := rand.NewStudentT(1)
+	c, _ := rand.NewCauchy(0, 1)
+	for _, x := range []float64{-1e6, -3, -0.5, 0, 0.1, 2, 1e9} {
+		if a, b := s.CDF(x), c.CDF(x); math.Abs(a-b) > 1e-12*math.Min(b, 1-b)+1e-15 {
+			t.Errorf("got CDF(%v) = %v instead of %v", x, a, b)
+		}
+		if a, b := s.PDF(x), c.PDF(x); math.Abs(a-b) > 1e-12*b {
+			t.Errorf("got PDF(%v) = %v instead of %v", x, a, b)
+		}
+	}
+}
+
+func TestLevy(t *testing.T) {
+	for _, c := range []struct{ loc, scale float64 }{{0, 1}, {-5, 0.01}, {1e3, 10}} {
+		d, err := rand.NewLevy(c.loc, c.scale)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "Lévy", d)
+	}
+	for _, c := range []struct{ loc, scale float64 }{{0, 0}, {0, -1}, {math.NaN(), 1}, {0, math.Inf(1)}} {
+		if _, err := rand.NewLevy(c.loc, c.scale); err == nil {
+			t.Errorf("got no error for loc %v, scale %v", c.loc, c.scale)
+		}
+	}
+}
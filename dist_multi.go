@@ -0,0 +1,204 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	covSymmetryTolerance = 1e-9  // relative difference allowed between cov[i][j] and cov[j][i]
+	covRankTolerance     = 1e-12 // pivots below this times n times the largest variance are treated as zero
+)
+
+// MultiNormal is the multivariate normal distribution with the given mean vector and covariance matrix.
+type MultiNormal struct {
+	n     int
+	mean  []float64
+	l     []float64 // n×n lower triangular factor of the (permuted) covariance matrix, row-major
+	swaps []int     // symmetric pivoting: row and column k were swapped with swaps[k]; nil without pivoting
+	rank  int
+}
+
+// NewMultiNormal returns the multivariate normal distribution with the given mean vector and covariance matrix.
+// It returns an error unless cov is a symmetric positive semi-definite len(mean)×len(mean) matrix,
+// and all numbers are finite.
+//
+// NewMultiNormal factors the covariance matrix using the Cholesky decomposition. If the matrix is singular,
+// it falls back to the Cholesky decomposition with symmetric pivoting, which handles semi-definite matrices
+// by setting the variance along the directions that have none to zero.
+func NewMultiNormal(mean []float64, cov [][]float64) (*MultiNormal, error) {
+	n := len(mean)
+	if n == 0 {
+		return nil, errors.New("rand: empty multivariate normal distribution mean")
+	}
+	if len(cov) != n {
+		return nil, fmt.Errorf("rand: %v×? covariance matrix for multivariate normal distribution with %v dimensions", len(cov), n)
+	}
+	for i, m := range mean {
+		if !isFinite(m) {
+			return nil, fmt.Errorf("rand: invalid multivariate normal distribution mean %v at index %v", m, i)
+		}
+	}
+	a := make([]float64, n*n)
+	maxVar := 0.0
+	for i, row := range cov {
+		if len(row) != n {
+			return nil, fmt.Errorf("rand: covariance matrix row %v has %v elements instead of %v", i, len(row), n)
+		}
+		for j, c := range row {
+			if !isFinite(c) {
+				return nil, fmt.Errorf("rand: invalid covariance %v at [%v][%v]", c, i, j)
+			}
+			if j < i && math.Abs(c-cov[j][i]) > covSymmetryTolerance*(math.Abs(c)+math.Abs(cov[j][i])) {
+				return nil, fmt.Errorf("rand: covariance matrix is not symmetric at [%v][%v]", i, j)
+			}
+			a[i*n+j] = c
+		}
+		if row[i] < 0 {
+			return nil, fmt.Errorf("rand: negative variance %v at index %v", row[i], i)
+		}
+		maxVar = math.Max(maxVar, row[i])
+	}
+
+	d := &MultiNormal{n: n, mean: append([]float64(nil), mean...), l: make([]float64, n*n), rank: n}
+	if choleskyLower(d.l, a, n) {
+		return d, nil
+	}
+	d.swaps = make([]int, n)
+	rank, ok := choleskyPivoted(d.l, d.swaps, a, n, float64(n)*covRankTolerance*maxVar)
+	if !ok {
+		return nil, errors.New("rand: covariance matrix is not positive semi-definite")
+	}
+	d.rank = rank
+	return d, nil
+}
+
+// choleskyLower sets l to the lower triangular factor of the positive definite n×n matrix a,
+// and reports whether a is positive definite.
+func choleskyLower(l []float64, a []float64, n int) bool {
+	for j := 0; j < n; j++ {
+		s := a[j*n+j]
+		for k := 0; k < j; k++ {
+			s -= l[j*n+k] * l[j*n+k]
+		}
+		if !(s > 0) {
+			return false
+		}
+		ljj := math.Sqrt(s)
+		l[j*n+j] = ljj
+		for i := j + 1; i < n; i++ {
+			s := a[i*n+j]
+			for k := 0; k < j; k++ {
+				s -= l[i*n+k] * l[j*n+k]
+			}
+			l[i*n+j] = s / ljj
+		}
+	}
+	return true
+}
+
+// choleskyPivoted sets l to the lower triangular factor of P^T*a*P, where the permutation P is the product
+// of swaps of k and swaps[k]. It overwrites a, and returns the rank of a, or false if a is not positive
+// semi-definite up to tol.
+func choleskyPivoted(l []float64, swaps []int, a []float64, n int, tol float64) (int, bool) {
+	for i := range l {
+		l[i] = 0
+	}
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if a[i*n+i] > a[p*n+p] {
+				p = i
+			}
+		}
+		swaps[k] = p
+		if p != k {
+			for i := 0; i < n; i++ {
+				a[i*n+k], a[i*n+p] = a[i*n+p], a[i*n+k]
+			}
+			for j := 0; j < n; j++ {
+				a[k*n+j], a[p*n+j] = a[p*n+j], a[k*n+j]
+			}
+			for j := 0; j < k; j++ {
+				l[k*n+j], l[p*n+j] = l[p*n+j], l[k*n+j]
+			}
+		}
+		if a[k*n+k] <= tol {
+			// the remaining Schur complement must be zero
+			for i := k; i < n; i++ {
+				for j := k; j < n; j++ {
+					if math.Abs(a[i*n+j]) > tol {
+						return 0, false
+					}
+				}
+				swaps[i] = i
+			}
+			return k, true
+		}
+		lkk := math.Sqrt(a[k*n+k])
+		l[k*n+k] = lkk
+		for i := k + 1; i < n; i++ {
+			l[i*n+k] = a[i*n+k] / lkk
+		}
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j <= i; j++ {
+				a[i*n+j] -= l[i*n+k] * l[j*n+k]
+				a[j*n+i] = a[i*n+j]
+			}
+		}
+	}
+	return n, true
+}
+
+// Len returns the number of dimensions.
+func (d *MultiNormal) Len() int {
+	return d.n
+}
+
+// Rank returns the rank of the covariance matrix: the number of dimensions of the subspace
+// that contains the samples, shifted by the mean.
+func (d *MultiNormal) Rank() int {
+	return d.rank
+}
+
+// Mean returns the mean vector.
+func (d *MultiNormal) Mean() []float64 {
+	return append([]float64(nil), d.mean...)
+}
+
+// Sample fills dst with a pseudo-random vector with the multivariate normal distribution.
+// It panics if len(dst) != [MultiNormal.Len].
+//
+// Sample transforms a vector of independent standard normal numbers from [Rand.NormFloat64s]
+// by the factor of the covariance matrix in place, so it does not allocate.
+func (d *MultiNormal) Sample(r *Rand, dst []float64) {
+	n := d.n
+	if len(dst) != n {
+		panic("invalid argument to Sample")
+	}
+	r.NormFloat64s(dst[:d.rank])
+	// dst = L * z; the rows are computed from the last one, which depends on all of z
+	for i := n - 1; i >= 0; i-- {
+		s := 0.0
+		for j := 0; j <= i && j < d.rank; j++ {
+			s += d.l[i*n+j] * dst[j]
+		}
+		dst[i] = s
+	}
+	if d.swaps != nil {
+		for k := n - 1; k >= 0; k-- {
+			p := d.swaps[k]
+			dst[k], dst[p] = dst[p], dst[k]
+		}
+	}
+	for i, m := range d.mean {
+		dst[i] += m
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+func checkMultiNormal(t *testing.T, mean []float64, cov [][]float64, rank int) {
+	t.Helper()
+	d, err := rand.NewMultiNormal(mean, cov)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if d.Len() != len(mean) || d.Rank() != rank {
+		t.Fatalf("got length %v and rank %v instead of %v and %v", d.Len(), d.Rank(), len(mean), rank)
+	}
+
+	n := len(mean)
+	r := rand.New(1)
+	x := make([]float64, n)
+	sum := make([]float64, n)
+	prod := make([][]float64, n)
+	for i := range prod {
+		prod[i] = make([]float64, n)
+	}
+	for k := 0; k < distSamples; k++ {
+		d.Sample(r, x)
+		for i := range x {
+			sum[i] += x[i] - mean[i]
+			for j := range x {
+				prod[i][j] += (x[i] - mean[i]) * (x[j] - mean[j])
+			}
+		}
+	}
+	for i := range mean {
+		sd := math.Sqrt(cov[i][i])
+		if m := sum[i] / distSamples; math.Abs(m) > 5*sd/math.Sqrt(distSamples)+1e-12 {
+			t.Errorf("got mean %v instead of %v at index %v", m+mean[i], mean[i], i)
+		}
+		for j := range mean {
+			c := prod[i][j] / distSamples
+			tol := 5*math.Sqrt((cov[i][i]*cov[j][j]+cov[i][j]*cov[i][j])/distSamples) + 1e-12
+			if math.Abs(c-cov[i][j]) > tol {
+				t.Errorf("got covariance %v instead of %v at [%v][%v]", c, cov[i][j], i, j)
+			}
+		}
+	}
+}
+
+func TestMultiNormal(t *testing.T) {
+	checkMultiNormal(t, []float64{1, -2, 3}, [][]float64{
+		{4, 1.2, -0.4},
+		{1.2, 1, 0.1},
+		{-0.4, 0.1, 0.25},
+	}, 3)
+}
+
+func TestMultiNormal_SemiDefinite(t *testing.T) {
+	// x2 = x0 + x1, and x3 is constant
+	checkMultiNormal(t, []float64{0, 1, 2, 3}, [][]float64{
+		{1, 0.5, 1.5, 0},
+		{0.5, 2, 2.5, 0},
+		{1.5, 2.5, 4, 0},
+		{0, 0, 0, 0},
+	}, 2)
+
+	d, _ := rand.NewMultiNormal([]float64{0, 1, 2, 3}, [][]float64{
+		{1, 0.5, 1.5, 0},
+		{0.5, 2, 2.5, 0},
+		{1.5, 2.5, 4, 0},
+		{0, 0, 0, 0},
+	})
+	r := rand.New(1)
+	x := make([]float64, d.Len())
+	for i := 0; i < small; i++ {
+		d.Sample(r, x)
+		if math.Abs(x[2]-2-(x[0]+x[1]-1)) > 1e-12 || x[3] != 3 {
+			t.Fatalf("got sample %v outside of the support", x)
+		}
+	}
+}
+
+func TestMultiNormal_Invalid(t *testing.T) {
+	for _, c := range []struct {
+		mean []float64
+		cov  [][]float64
+	}{
+		{nil, nil},
+		{[]float64{0, 0}, [][]float64{{1, 0}}},
+		{[]float64{0, 0}, [][]float64{{1, 0}, {0}}},
+		{[]float64{math.NaN()}, [][]float64{{1}}},
+		{[]float64{0}, [][]float64{{math.Inf(1)}}},
+		{[]float64{0}, [][]float64{{-1}}},
+		{[]float64{0, 0}, [][]float64{{1, 0.5}, {0.4, 1}}},
+		{[]float64{0, 0}, [][]float64{{1, 2}, {2, 1}}},
+		{[]float64{0, 0, 0}, [][]float64{{1, 0, 0}, {0, 0, 1}, {0, 1, 0}}},
+	} {
+		if _, err := rand.NewMultiNormal(c.mean, c.cov); err == nil {
+			t.Errorf("got no error for mean %v and covariance %v", c.mean, c.cov)
+		}
+	}
+}
+
+func TestRand_NormFloat64s(t *testing.T) {
+	r1 := rand.New(1)
+	r2 := rand.New(1)
+	x := make([]float64, tiny)
+	r1.NormFloat64s(x)
+	for i, v := range x {
+		if w := r2.NormFloat64(); v != w {
+			t.Fatalf("got %v instead of %v at index %v", v, w, i)
+		}
+	}
+}
+
+func TestRand_NormComplex128(t *testing.T) {
+	r := rand.New(1)
+	var abs2, re2, im2, reim float64
+	for i := 0; i < distSamples; i++ {
+		z := r.NormComplex128()
+		re, im := real(z), imag(z)
+		abs2 += re*re + im*im
+		re2 += re * re
+		im2 += im * im
+		reim += re * im
+	}
+	tol := 5 / math.Sqrt(distSamples)
+	if m := abs2 / distSamples; math.Abs(m-1) > tol {
+		t.Errorf("got E[|z|^2] = %v instead of 1", m)
+	}
+	if m := re2 / distSamples; math.Abs(m-0.5) > tol {
+		t.Errorf("got real part variance %v instead of 1/2", m)
+	}
+	if m := im2 / distSamples; math.Abs(m-0.5) > tol {
+		t.Errorf("got imaginary part variance %v instead of 1/2", m)
+	}
+	if m := reim / distSamples; math.Abs(m) > tol {
+		t.Errorf("got real and imaginary part covariance %v instead of 0", m)
+	}
+}
+
+func BenchmarkMultiNormal_Sample(b *testing.B) {
+	d, _ := rand.NewMultiNormal([]float64{1, -2, 3}, [][]float64{
+		{4, 1.2, -0.4},
+		{1.2, 1, 0.1},
+		{-0.4, 0.1, 0.25},
+	})
+	r := rand.New(1)
+	x := make([]float64, d.Len())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Sample(r, x)
+	}
+	sinkFloat64 = x[0]
+}
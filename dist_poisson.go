@@ -0,0 +1,339 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	poissonMaxLambda  = 0x1p60 // keeps samples well within uint64
+	poissonPTRSLambda = 10     // PTRS is faster than multiplication from here on
+	binomialBTPEMean  = 30     // BTPE is faster than inversion from here on
+)
+
+var (
+	_ Discrete = (*Poisson)(nil)
+	_ Discrete = (*Binomial)(nil)
+)
+
+// Poisson is the Poisson distribution with mean λ.
+type Poisson struct {
+	lambda    float64
+	logLambda float64
+	expNeg    float64 // e^(-λ), for multiplication
+	// PTRS constants
+	a        float64
+	b        float64
+	vr       float64
+	logAlpha float64 // log(1/α)
+}
+
+// NewPoisson returns the Poisson distribution with mean lambda.
+// It returns an error unless lambda is positive and at most 2^60.
+func NewPoisson(lambda float64) (*Poisson, error) {
+	if !(lambda > 0 && lambda <= poissonMaxLambda) {
+		return nil, fmt.Errorf("rand: invalid Poisson distribution mean %v", lambda)
+	}
+	d := &Poisson{}
+	d.init(lambda)
+	return d, nil
+}
+
+func (d *Poisson) init(lambda float64) {
+	*d = Poisson{lambda: lambda, logLambda: math.Log(lambda)}
+	if lambda < poissonPTRSLambda {
+		d.expNeg = math.Exp(-lambda)
+	} else {
+		d.b = 0.931 + 2.53*math.Sqrt(lambda)
+		d.a = -0.059 + 0.02483*d.b
+		d.vr = 0.9277 - 3.6224/(d.b-2)
+		d.logAlpha = math.Log(1.1239 + 1.1328/(d.b-3.4))
+	}
+}
+
+// Sample returns a Poisson distributed pseudo-random number.
+//
+// For λ < 10, Sample uses Knuth's multiplication of uniform numbers, which needs λ+1 of them on average.
+// For larger λ, it uses the transformed rejection with squeeze (PTRS) by Hörmann,
+// which needs O(1) time regardless of λ.
+func (d *Poisson) Sample(r *Rand) uint64 {
+	if d.lambda < poissonPTRSLambda {
+		k := uint64(0)
+		p := r.Float64()
+		for p > d.expNeg {
+			k++
+			p *= r.Float64()
+		}
+		return k
+	}
+	for {
+		u := r.Float64() - 0.5
+		v := r.Float64()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*d.a/us+d.b)*u + d.lambda + 0.43)
+		if us >= 0.07 && v <= d.vr {
+			return uint64(k)
+		}
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+		if math.Log(v)+d.logAlpha-math.Log(d.a/(us*us)+d.b) <= -d.lambda+k*d.logLambda-lgamma(k+1) {
+			return uint64(k)
+		}
+	}
+}
+
+// PMF returns the probability that a sample is equal to k.
+func (d *Poisson) PMF(k uint64) float64 {
+	return dpoisRaw(float64(k), d.lambda)
+}
+
+// CDF returns the probability that a sample is less than or equal to k.
+func (d *Poisson) CDF(k uint64) float64 {
+	return gammaIncQ(float64(k)+1, d.lambda)
+}
+
+// Quantile returns the smallest k such that CDF(k) >= p. It panics if p is not in [0, 1].
+func (d *Poisson) Quantile(p float64) uint64 {
+	checkQuantile(p)
+	return searchQuantile(d.CDF, p, math.MaxUint64)
+}
+
+// Mean returns the expected value.
+func (d *Poisson) Mean() float64 {
+	return d.lambda
+}
+
+// Variance returns the variance.
+func (d *Poisson) Variance() float64 {
+	return d.lambda
+}
+
+// Binomial is the binomial distribution: the number of successes in n independent trials
+// with success probability p.
+type Binomial struct {
+	n    uint64
+	p    float64
+	r    float64 // min(p, 1-p); samples for p > 0.5 are reflected
+	q    float64 // 1-r
+	mean float64 // n*r
+	// inversion constants
+	qn    float64 // q^n
+	bound float64
+	// BTPE constants
+	m, xm, xl, xr, c, laml, lamr, p1, p2, p3, p4 float64
+}
+
+// NewBinomial returns the binomial distribution with n trials and success probability p.
+// It returns an error unless p is in [0, 1].
+func NewBinomial(n uint64, p float64) (*Binomial, error) {
+	if !(p >= 0 && p <= 1) {
+		return nil, fmt.Errorf("rand: invalid binomial distribution probability %v", p)
+	}
+	d := &Binomial{}
+	d.init(n, p)
+	return d, nil
+}
+
+func (d *Binomial) init(n uint64, p float64) {
+	*d = Binomial{n: n, p: p, r: math.Min(p, 1-p)}
+	d.q = 1 - d.r
+	nf := float64(n)
+	d.mean = nf * d.r
+	if d.mean < binomialBTPEMean {
+		d.qn = math.Exp(nf * math.Log1p(-d.r))
+		d.bound = math.Min(nf, d.mean+10*math.Sqrt(d.mean*d.q+1))
+		return
+	}
+	fm := d.mean + d.r
+	d.m = math.Floor(fm)
+	d.p1 = math.Floor(2.195*math.Sqrt(d.mean*d.q)-4.6*d.q) + 0.5
+	d.xm = d.m + 0.5
+	d.xl = d.xm - d.p1
+	d.xr = d.xm + d.p1
+	d.c = 0.134 + 20.5/(15.3+d.m)
+	a := (fm - d.xl) / (fm - d.xl*d.r)
+	d.laml = a * (1 + a/2)
+	a = (d.xr - fm) / (d.xr * d.q)
+	d.lamr = a * (1 + a/2)
+	d.p2 = d.p1 * (1 + 2*d.c)
+	d.p3 = d.p2 + d.c/d.laml
+	d.p4 = d.p3 + d.c/d.lamr
+}
+
+// Sample returns a binomially distributed pseudo-random number.
+//
+// For n*min(p, 1-p) < 30, Sample uses inversion by sequential search, which needs O(n*p) time.
+// Otherwise, it uses the BTPE algorithm by Kachitvichyanukul and Schmeiser,
+// which needs O(1) time regardless of n.
+func (d *Binomial) Sample(r *Rand) uint64 {
+	if d.r == 0 {
+		if d.p == 1 {
+			return d.n
+		}
+		return 0
+	}
+	var k uint64
+	if d.mean < binomialBTPEMean {
+		k = d.inversion(r)
+	} else {
+		k = d.btpe(r)
+	}
+	if d.p > 0.5 {
+		return d.n - k
+	}
+	return k
+}
+
+func (d *Binomial) inversion(r *Rand) uint64 {
+	s := d.r / d.q
+	for {
+		x := 0.0
+		px := d.qn
+		u := r.Float64()
+		for u > px {
+			x++
+			if x > d.bound { // rounding errors accumulated; start over
+				break
+			}
+			u -= px
+			px *= (float64(d.n) - x + 1) * s / x
+		}
+		if x <= d.bound {
+			return uint64(x)
+		}
+	}
+}
+
+func (d *Binomial) btpe(r *Rand) uint64 {
+	n := float64(d.n)
+	nrq := d.mean * d.q
+	for {
+		u := r.Float64() * d.p4
+		v := r.Float64()
+		var y float64
+		switch {
+		case u <= d.p1: // triangular region
+			return uint64(math.Floor(d.xm - d.p1*v + u))
+		case u <= d.p2: // parallelograms
+			x := d.xl + (u-d.p1)/d.c
+			v = v*d.c + 1 - math.Abs(d.m-x+0.5)/d.p1
+			if v > 1 {
+				continue
+			}
+			y = math.Floor(x)
+		case u <= d.p3: // left exponential tail
+			y = math.Floor(d.xl + math.Log(v)/d.laml)
+			if y < 0 {
+				continue
+			}
+			v *= (u - d.p2) * d.laml
+		default: // right exponential tail
+			y = math.Floor(d.xr - math.Log(v)/d.lamr)
+			if y > n {
+				continue
+			}
+			v *= (u - d.p3) * d.lamr
+		}
+
+		k := math.Abs(y - d.m)
+		if k <= 20 || k >= nrq/2-1 {
+			// explicit evaluation of f(y) / f(m)
+			s := d.r / d.q
+			a := s * (n + 1)
+			f := 1.0
+			if d.m < y {
+				for i := d.m + 1; i <= y; i++ {
+					f *= a/i - s
+				}
+			} else if d.m > y {
+				for i := y + 1; i <= d.m; i++ {
+					f /= a/i - s
+				}
+			}
+			if v <= f {
+				return uint64(y)
+			}
+			continue
+		}
+
+		// squeeze using upper and lower bounds on log(f(y) / f(m))
+		rho := (k / nrq) * ((k*(k/3+0.625)+1.0/6)/nrq + 0.5)
+		t := -k * k / (2 * nrq)
+		l := math.Log(v)
+		if l < t-rho {
+			return uint64(y)
+		}
+		if l > t+rho {
+			continue
+		}
+		if l <= binomialLogRatio(n, d.m, y, d.r, d.q) {
+			return uint64(y)
+		}
+	}
+}
+
+// binomialLogRatio returns log(f(y) / f(m)) for the binomial PMF f with n trials and success probability r = 1-q,
+// using Stirling's formula with corrections. The BTPE paper adds all four corrections;
+// the ones of y and n-y must be subtracted.
+func binomialLogRatio(n float64, m float64, y float64, r float64, q float64) float64 {
+	x1 := y + 1
+	f1 := m + 1
+	z := n + 1 - m
+	w := n - y + 1
+	return (m+0.5)*math.Log(f1/x1) + (n-m+0.5)*math.Log(z/w) + (y-m)*math.Log(w*r/(x1*q)) +
+		stirlerr(f1) + stirlerr(z) - stirlerr(x1) - stirlerr(w)
+}
+
+// PMF returns the probability that a sample is equal to k.
+func (d *Binomial) PMF(k uint64) float64 {
+	if k > d.n {
+		return 0
+	}
+	switch d.p {
+	case 0:
+		if k == 0 {
+			return 1
+		}
+		return 0
+	case 1:
+		if k == d.n {
+			return 1
+		}
+		return 0
+	}
+	return dbinomRaw(float64(k), float64(d.n), d.p, 1-d.p)
+}
+
+// CDF returns the probability that a sample is less than or equal to k.
+func (d *Binomial) CDF(k uint64) float64 {
+	switch {
+	case k >= d.n || d.p == 0:
+		return 1
+	case d.p == 1:
+		return 0
+	}
+	return betaInc(float64(d.n-k), float64(k)+1, 1-d.p)
+}
+
+// Quantile returns the smallest k such that CDF(k) >= p. It panics if p is not in [0, 1].
+func (d *Binomial) Quantile(p float64) uint64 {
+	checkQuantile(p)
+	return searchQuantile(d.CDF, p, d.n)
+}
+
+// Mean returns the expected value.
+func (d *Binomial) Mean() float64 {
+	return float64(d.n) * d.p
+}
+
+// Variance returns the variance.
+func (d *Binomial) Variance() float64 {
+	return float64(d.n) * d.p * (1 - d.p)
+}
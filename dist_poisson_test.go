@@ -0,0 +1,126 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+func TestPoisson(t *testing.T) {
+	for _, lambda := range []float64{0.01, 1, 9.5, 10, 30, 500} {
+		d, err := rand.NewPoisson(lambda)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkDiscrete(t, "Poisson", d)
+	}
+	for _, lambda := range []float64{1e4, 1e7, 1e9} {
+		d, _ := rand.NewPoisson(lambda)
+		checkDiscreteWide(t, "Poisson", d)
+	}
+	for _, lambda := range []float64{0, -1, math.NaN(), math.Inf(1), 0x1p61} {
+		if _, err := rand.NewPoisson(lambda); err == nil {
+			t.Errorf("got no error for lambda %v", lambda)
+		}
+	}
+}
+
+func TestBinomial(t *testing.T) {
+	for _, c := range []struct {
+		n uint64
+		p float64
+	}{{0, 0.5}, {1, 0.5}, {10, 0}, {10, 1}, {20, 0.3}, {1000, 0.001}, {100, 0.9}, {200, 0.5}, {5000, 0.02}, {1000, 0.97}} {
+		d, err := rand.NewBinomial(c.n, c.p)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkDiscrete(t, "binomial", d)
+	}
+	for _, c := range []struct {
+		n uint64
+		p float64
+	}{{1e6, 0.5}, {1e9, 1e-3}, {1e10, 0.75}} {
+		d, _ := rand.NewBinomial(c.n, c.p)
+		checkDiscreteWide(t, "binomial", d)
+	}
+	for _, p := range []float64{-0.1, 1.1, math.NaN()} {
+		if _, err := rand.NewBinomial(10, p); err == nil {
+			t.Errorf("got no error for p %v", p)
+		}
+	}
+}
+
+func TestBinomial_Squeeze(t *testing.T) {
+	// BTPE evaluates f(y) / f(m) with Stirling's formula for 20 < |y - m| < n*p*q/2 - 1
+	const n, p = 1e4, 0.3
+	logPMF := func(k float64) float64 {
+		a, _ := math.Lgamma(n + 1)
+		b, _ := math.Lgamma(k + 1)
+		c, _ := math.Lgamma(n - k + 1)
+		return a - b - c + k*math.Log(p) + (n-k)*math.Log(1-p)
+	}
+	const m = 3000
+	for _, y := range []float64{m - 1000, m - 300, m - 100, m - 21, m + 21, m + 100, m + 300, m + 1000} {
+		want := logPMF(y) - logPMF(m)
+		if got := rand.BinomialLogRatioForTest(n, m, y, p, 1-p); math.Abs(got-want) > 1e-9*math.Max(1, math.Abs(want)) {
+			t.Errorf("got log(f(%v) / f(%v)) = %v instead of %v", y, m, got, want)
+		}
+	}
+
+	const samples = 1 << 20
+	d, _ := rand.NewBinomial(n, p)
+	r := rand.New(1)
+	counts := make([]int, n+1)
+	for i := 0; i < samples; i++ {
+		counts[d.Sample(r)]++
+	}
+	// values within 20 of the mode are lumped into a single bin, as are values with a small expected count
+	var binCounts []int
+	var expected []float64
+	var c int
+	var e float64
+	for k := range counts {
+		c += counts[k]
+		e += samples * d.PMF(uint64(k))
+		if (k < m-20 || k >= m+20) && e >= 20 {
+			binCounts = append(binCounts, c)
+			expected = append(expected, e)
+			c, e = 0, 0
+		}
+	}
+	binCounts[len(binCounts)-1] += c
+	expected[len(expected)-1] += e
+	checkChiSquared(t, binCounts, expected)
+}
+
+func BenchmarkPoisson(b *testing.B) {
+	for _, lambda := range []float64{1, 1e7} {
+		d, _ := rand.NewPoisson(lambda)
+		b.Run(fmt.Sprint(lambda), func(b *testing.B) {
+			r := rand.New(1)
+			for i := 0; i < b.N; i++ {
+				sinkUint64 = d.Sample(r)
+			}
+		})
+	}
+}
+
+func BenchmarkBinomial(b *testing.B) {
+	for _, n := range []uint64{10, 1e9} {
+		d, _ := rand.NewBinomial(n, 0.3)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			r := rand.New(1)
+			for i := 0; i < b.N; i++ {
+				sinkUint64 = d.Sample(r)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+const distSamples = 1 << 16
+
+// checkContinuous checks that the functions of d are consistent with each other,
+// and that the samples of d fit its CDF.
+func checkContinuous(t *testing.T, name string, d rand.Continuous) {
+	t.Helper()
+	h := 1e-5 * (d.Quantile(0.75) - d.Quantile(0.25))
+	for _, p := range []float64{1e-6, 0.001, 0.1, 0.25, 0.5, 0.75, 0.9, 0.999, 1 - 1e-6} {
+		x := d.Quantile(p)
+		if c := d.CDF(x); math.Abs(c-p) > 1e-9+1e-6*math.Min(p, 1-p) {
+			t.Errorf("%v: CDF(Quantile(%v)) = %v", name, p, c)
+		}
+		hx := h
+		if x > 0 && x < 1e3*h { // close to the boundary of a positive support
+			hx = 1e-3 * x
+		}
+		if lo, hi := d.CDF(x-hx), d.CDF(x+hx); lo > 0 && hi < 1 {
+			num := (hi - lo) / (2 * hx)
+			if pdf := d.PDF(x); math.Abs(num-pdf) > 1e-3*pdf+1e-9 {
+				t.Errorf("%v: PDF(%v) = %v, CDF derivative is %v", name, x, pdf, num)
+			}
+		}
+	}
+
+	const bins = 32
+	r := rand.New(1)
+	counts := make([]int, bins)
+	expected := make([]float64, bins)
+	var sum, sumSq float64
+	for i := 0; i < distSamples; i++ {
+		x := d.Sample(r)
+		b := int(d.CDF(x) * bins)
+		if b == bins {
+			b--
+		}
+		counts[b]++
+		sum += x
+		sumSq += x * x
+	}
+	for i := range expected {
+		expected[i] = distSamples / bins
+	}
+	checkChiSquared(t, counts, expected)
+
+	if v := d.Variance(); isFinite(v) {
+		mean := sum / distSamples
+		if m := d.Mean(); math.Abs(mean-m) > 6*math.Sqrt(v/distSamples) {
+			t.Errorf("%v: sample mean %v, expected %v", name, mean, m)
+		}
+	}
+}
+
+// checkDiscrete checks that the functions of d are consistent with each other,
+// and that the samples of d fit its PMF.
+func checkDiscrete(t *testing.T, name string, d rand.Discrete) {
+	t.Helper()
+	cdf := 0.0
+	k := uint64(0)
+	for ; cdf < 1-1e-9 && k < 10000; k++ {
+		cdf += d.PMF(k)
+		if c := d.CDF(k); math.Abs(c-cdf) > 1e-9 {
+			t.Fatalf("%v: CDF(%v) = %v, sum of PMF is %v", name, k, c, cdf)
+		}
+	}
+	for _, p := range []float64{0, 1e-6, 0.001, 0.1, 0.25, 0.5, 0.75, 0.9, 0.999, 1 - 1e-6} {
+		q := d.Quantile(p)
+		if d.CDF(q) < p || (q > 0 && d.CDF(q-1) >= p) {
+			t.Errorf("%v: Quantile(%v) = %v, CDF around it is %v, %v", name, p, q, d.CDF(q-1), d.CDF(q))
+		}
+	}
+
+	// outcomes after the first one with a small expected count are lumped into the last bin
+	var expected []float64
+	for i := uint64(0); ; i++ {
+		e := distSamples * d.PMF(i)
+		if e < 20 && (e > 0 || d.CDF(i) > 1e-9) {
+			break
+		}
+		expected = append(expected, e)
+	}
+	n := uint64(len(expected))
+	tail := 1.0
+	if n > 0 {
+		tail = 1 - d.CDF(n-1)
+	}
+	expected = append(expected, distSamples*tail)
+	counts := make([]int, len(expected))
+	r := rand.New(1)
+	var sum float64
+	for i := 0; i < distSamples; i++ {
+		k := d.Sample(r)
+		sum += float64(k)
+		if k > n {
+			k = n
+		}
+		counts[k]++
+	}
+	checkChiSquared(t, counts, expected)
+
+	if v := d.Variance(); isFinite(v) {
+		mean := sum / distSamples
+		if m := d.Mean(); math.Abs(mean-m) > 6*math.Sqrt(v/distSamples) {
+			t.Errorf("%v: sample mean %v, expected %v", name, mean, m)
+		}
+	}
+}
+
+// checkDiscreteWide is checkDiscrete for distributions with too many outcomes
+// to enumerate: it checks the PMF against CDF differences around the quantiles,
+// and fits the samples into bins between the quantiles.
+func checkDiscreteWide(t *testing.T, name string, d rand.Discrete) {
+	t.Helper()
+	const bins = 32
+	edges := []uint64{0}
+	for i := 1; i < bins; i++ {
+		q := d.Quantile(float64(i) / bins)
+		if q > 0 {
+			// CDF differences lose precision to cancellation, which is significant for tiny PMF values
+			if c, pmf := d.CDF(q)-d.CDF(q-1), d.PMF(q); math.Abs(c-pmf) > 1e-6*pmf+1e-10 {
+				t.Errorf("%v: PMF(%v) = %v, CDF difference is %v", name, q, pmf, c)
+			}
+		}
+		if q > edges[len(edges)-1] {
+			edges = append(edges, q)
+		}
+	}
+	// bin i contains [edges[i], edges[i+1])
+	expected := make([]float64, len(edges))
+	prev := 0.0
+	for i := 1; i < len(edges); i++ {
+		c := d.CDF(edges[i] - 1)
+		expected[i-1] = distSamples * (c - prev)
+		prev = c
+	}
+	expected[len(edges)-1] = distSamples * (1 - prev)
+
+	counts := make([]int, len(edges))
+	r := rand.New(1)
+	var sum float64
+	for i := 0; i < distSamples; i++ {
+		k := d.Sample(r)
+		sum += float64(k)
+		counts[sort.Search(len(edges), func(j int) bool { return edges[j] > k })-1]++
+	}
+	checkChiSquared(t, counts, expected)
+
+	mean := sum / distSamples
+	if m := d.Mean(); math.Abs(mean-m) > 6*math.Sqrt(d.Variance()/distSamples) {
+		t.Errorf("%v: sample mean %v, expected %v", name, mean, m)
+	}
+}
+
+func isFinite(x float64) bool {
+	return !math.IsInf(x, 0) && !math.IsNaN(x)
+}
+
+func TestNormal(t *testing.T) {
+	for _, c := range []struct{ mean, sd float64 }{{0, 1}, {-3, 0.01}, {1e6, 1e3}} {
+		d, err := rand.NewNormal(c.mean, c.sd)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "normal", d)
+	}
+	for _, c := range []struct{ mean, sd float64 }{{0, 0}, {0, -1}, {math.NaN(), 1}, {0, math.Inf(1)}} {
+		if _, err := rand.NewNormal(c.mean, c.sd); err == nil {
+			t.Errorf("got no error for mean %v, sd %v", c.mean, c.sd)
+		}
+	}
+}
+
+func TestExponential(t *testing.T) {
+	for _, rate := range []float64{1, 0.01, 100} {
+		d, err := rand.NewExponential(rate)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, "exponential", d)
+	}
+	for _, rate := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := rand.NewExponential(rate); err == nil {
+			t.Errorf("got no error for rate %v", rate)
+		}
+	}
+}
+
+func TestZipf(t *testing.T) {
+	for _, c := range []struct {
+		s, v float64
+		imax uint64
+	}{{1.1, 1, 100}, {2, 1, 10}, {3, 5, math.MaxUint64}, {1.5, 2, 1 << 40}, {1, 1, 100}, {0.99, 1, 1000}, {0.5, 3, 50}, {1e-3, 1, 20}} {
+		d, err := rand.NewZipfDistribution(c.s, c.v, c.imax)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkDiscrete(t, "zipf", d)
+	}
+	for _, c := range []struct {
+		s, v float64
+		imax uint64
+	}{{0.99, 1, 1 << 40}, {1, 2, 1e9}, {0.5, 1, math.MaxUint64}, {1 - 1e-9, 1, 1 << 50}} {
+		d, err := rand.NewZipfDistribution(c.s, c.v, c.imax)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkDiscreteWide(t, "zipf", d)
+	}
+	for _, c := range []struct{ s, v float64 }{{0, 1}, {-1, 1}, {2, 0.5}, {math.NaN(), 1}, {2, math.NaN()}} {
+		if _, err := rand.NewZipfDistribution(c.s, c.v, 10); err == nil {
+			t.Errorf("got no error for s %v, v %v", c.s, c.v)
+		}
+		if rand.NewZipf(rand.New(1), c.s, c.v, 10) != nil {
+			t.Errorf("got non-nil Zipf for s %v, v %v", c.s, c.v)
+		}
+	}
+}
+
+func TestZipf_Moments(t *testing.T) {
+	const imax = 50
+	for _, s := range []float64{1.5, 1, 0.7} {
+		d, _ := rand.NewZipfDistribution(s, 2, imax)
+		var mean, sq float64
+		for k := uint64(0); k <= imax; k++ {
+			mean += float64(k) * d.PMF(k)
+			sq += float64(k*k) * d.PMF(k)
+		}
+		if math.Abs(d.Mean()-mean) > 1e-9*mean || math.Abs(d.Variance()-(sq-mean*mean)) > 1e-9*sq {
+			t.Errorf("s %v: got mean %v, variance %v instead of %v, %v", s, d.Mean(), d.Variance(), mean, sq-mean*mean)
+		}
+	}
+}
+
+func TestZipf_Grow(t *testing.T) {
+	for _, s := range []float64{2, 0.99} {
+		z, _ := rand.NewZipfDistribution(s, 1, 10)
+		d := z.Grow(1000)
+		e, _ := rand.NewZipfDistribution(s, 1, 1000)
+		r1 := rand.New(1)
+		r2 := rand.New(1)
+		for i := 0; i < small; i++ {
+			if a, b := d.Sample(r1), e.Sample(r2); a != b {
+				t.Fatalf("s %v: grown Zipf returned %v, new Zipf returned %v", s, a, b)
+			}
+		}
+		if d.PMF(500) != e.PMF(500) || d.CDF(500) != e.CDF(500) || d.Mean() != e.Mean() {
+			t.Errorf("s %v: grown Zipf has PMF %v, CDF %v, mean %v instead of %v, %v, %v",
+				s, d.PMF(500), d.CDF(500), d.Mean(), e.PMF(500), e.CDF(500), e.Mean())
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("s %v: Grow to a smaller imax did not panic", s)
+				}
+			}()
+			d.Grow(999)
+		}()
+		if z.PMF(11) != 0 {
+			t.Errorf("s %v: Grow changed the original distribution", s)
+		}
+	}
+}
+
+func TestScrambledZipf(t *testing.T) {
+	const n = 100
+	d, err := rand.NewScrambledZipf(rand.New(1), 0.99, 1, n)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	z, _ := rand.NewZipfDistribution(0.99, 1, n-1)
+	expected := make([]float64, n)
+	hot := 0
+	for k := uint64(0); k < n; k++ {
+		if p := z.PMF(d.Rank(k)); d.PMF(k) != p {
+			t.Fatalf("got PMF(%v) = %v instead of %v", k, d.PMF(k), p)
+		}
+		expected[k] = distSamples * d.PMF(k)
+		if d.Rank(k) < 10 && k < 10 {
+			hot++
+		}
+	}
+	if hot == 10 {
+		t.Errorf("the most popular keys are not scrambled")
+	}
+	counts := make([]int, n)
+	r := rand.New(2)
+	for i := 0; i < distSamples; i++ {
+		counts[d.Sample(r)]++
+	}
+	checkChiSquared(t, counts, expected)
+
+	if _, err := rand.NewScrambledZipf(rand.New(1), 0.99, 1, 0); err == nil {
+		t.Errorf("got no error for 0 keys")
+	}
+}
+
+func TestLatestZipf(t *testing.T) {
+	d, err := rand.NewLatestZipf(0.99, 1, 100)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	checkDiscrete(t, "latest zipf", d)
+	if d.PMF(99) <= d.PMF(98) || d.PMF(100) != 0 {
+		t.Errorf("got PMF %v, %v, %v at the last keys", d.PMF(98), d.PMF(99), d.PMF(100))
+	}
+
+	d = d.Grow(1 << 30)
+	e, _ := rand.NewLatestZipf(0.99, 1, 1<<30)
+	if d.Len() != 1<<30 || d.PMF(1<<29) != e.PMF(1<<29) || d.Mean() != e.Mean() {
+		t.Errorf("got length %v, PMF %v, mean %v after Grow instead of %v, %v, %v",
+			d.Len(), d.PMF(1<<29), d.Mean(), e.Len(), e.PMF(1<<29), e.Mean())
+	}
+	checkDiscreteWide(t, "latest zipf", d)
+
+	if _, err := rand.NewLatestZipf(0.99, 1, 0); err == nil {
+		t.Errorf("got no error for 0 keys")
+	}
+}
+
+func TestZipf_Uint64(t *testing.T) {
+	z := rand.NewZipf(rand.New(1), 2, 1, 100)
+	d, _ := rand.NewZipfDistribution(2, 1, 100)
+	r := rand.New(1)
+	for i := 0; i < 100; i++ {
+		if a, b := z.Uint64(), d.Sample(r); a != b {
+			t.Fatalf("Uint64 returned %v, Sample returned %v", a, b)
+		}
+	}
+}
+
+func TestDistributions_Shared(t *testing.T) {
+	const goroutines = 8
+	const samples = 1000
+	zipf, _ := rand.NewZipfDistribution(0.99, 1, 1<<20)
+	binomial, _ := rand.NewBinomial(1000, 0.3)
+	hypergeometric, _ := rand.NewHypergeometric(1000, 300, 100)
+	gamma, _ := rand.NewGamma(0.5, 2)
+	trunc, _ := rand.NewTruncNormal(0, 1, 3, 4)
+	discrete := []rand.Discrete{zipf, binomial, hypergeometric}
+	continuous := []rand.Continuous{gamma, trunc}
+
+	draw := func(seed uint64) []float64 {
+		r := rand.New(seed)
+		var s []float64
+		for i := 0; i < samples; i++ {
+			for _, d := range discrete {
+				s = append(s, float64(d.Sample(r)))
+			}
+			for _, d := range continuous {
+				s = append(s, d.Sample(r))
+			}
+		}
+		return s
+	}
+	var want [goroutines][]float64
+	for i := range want {
+		want[i] = draw(uint64(i))
+	}
+	var got [goroutines][]float64
+	var wg sync.WaitGroup
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = draw(uint64(i))
+		}(i)
+	}
+	wg.Wait()
+	for i := range got {
+		for j := range got[i] {
+			if got[i][j] != want[i][j] {
+				t.Fatalf("goroutine %v: got sample %v instead of %v at index %v", i, got[i][j], want[i][j], j)
+			}
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	normalTailAsymptotic       = 30  // log Q(x) uses the asymptotic expansion from here on
+	truncNormalQuadratureRange = 40  // log of the density ratio across intervals whose moments are integrated numerically
+	truncNormalQuadratureNodes = 32  // enough for densities that change by a factor of e^40
+	truncNormalFractionBound   = 5   // lower bound from which the Mills ratio continued fraction converges quickly
+	truncNormalFractionTerms   = 200 // terms of the Mills ratio continued fraction
+)
+
+var _ Continuous = (*TruncNormal)(nil)
+
+// TruncNormal is the normal distribution truncated to the interval [lo, hi].
+type TruncNormal struct {
+	mean float64
+	sd   float64
+	lo   float64
+	hi   float64
+	// the sampler works with the standard normal distribution truncated to [a, b],
+	// mirrored if the original interval is entirely below the mean, so that b > 0
+	a, b   float64
+	mirror bool
+	tail   bool    // a >= 0
+	la, lb float64 // log Q(a), log Q(b) for tail; otherwise, norm = Φ(b) - Φ(a)
+	norm   float64
+}
+
+// NewTruncNormal returns the normal distribution with the given mean and standard deviation, truncated to [lo, hi].
+// The bounds can be infinite. It returns an error unless mean is finite, sd is finite and positive,
+// and lo < hi.
+func NewTruncNormal(mean float64, sd float64, lo float64, hi float64) (*TruncNormal, error) {
+	if !isFinite(mean) || !isFinite(sd) || sd <= 0 || !(lo < hi) {
+		return nil, fmt.Errorf("rand: invalid truncated normal distribution parameters mean=%v sd=%v lo=%v hi=%v", mean, sd, lo, hi)
+	}
+	d := &TruncNormal{mean: mean, sd: sd, lo: lo, hi: hi, a: (lo - mean) / sd, b: (hi - mean) / sd}
+	if d.b <= 0 {
+		d.a, d.b = -d.b, -d.a
+		d.mirror = true
+	}
+	if d.a >= 0 {
+		d.tail = true
+		d.la, d.lb = logNormalTail(d.a), logNormalTail(d.b)
+	} else {
+		d.norm = normalDiff(d.a, d.b)
+	}
+	return d, nil
+}
+
+// logNormalTail returns log Q(x), the logarithm of the probability that a standard normal number is greater than x.
+func logNormalTail(x float64) float64 {
+	if x < normalTailAsymptotic {
+		return math.Log(math.Erfc(x/math.Sqrt2) / 2)
+	}
+	if math.IsInf(x, 1) {
+		return math.Inf(-1)
+	}
+	x2 := 1 / (x * x)
+	return -x*x/2 - math.Log(x) - math.Log(2*math.Pi)/2 + math.Log1p(-x2*(1-x2*(3-x2*(15-x2*105))))
+}
+
+// logNormalPDF returns the logarithm of the standard normal probability density function.
+func logNormalPDF(x float64) float64 {
+	return -x*x/2 - math.Log(2*math.Pi)/2
+}
+
+// normalDiff returns Φ(y) - Φ(x) for x <= y, without cancellation.
+func normalDiff(x float64, y float64) float64 {
+	switch {
+	case y <= 0:
+		return (math.Erfc(-y/math.Sqrt2) - math.Erfc(-x/math.Sqrt2)) / 2
+	case x >= 0:
+		return (math.Erfc(x/math.Sqrt2) - math.Erfc(y/math.Sqrt2)) / 2
+	default:
+		return (math.Erf(y/math.Sqrt2) - math.Erf(x/math.Sqrt2)) / 2
+	}
+}
+
+// uniformRejectionWidth returns the interval width [a, b] for a >= 0 below which uniform rejection
+// is faster than Robert's exponential rejection.
+func uniformRejectionWidth(a float64) float64 {
+	s := math.Sqrt(a*a + 4)
+	return 2 * math.Sqrt(math.E) / (a + s) * math.Exp((a*a-a*s)/4)
+}
+
+// Sample returns a pseudo-random number with the truncated normal distribution.
+//
+// For intervals away from the mean, Sample uses Robert's rejection from the translated exponential distribution,
+// or rejection from the uniform distribution if the interval is narrow; for intervals that contain the mean,
+// it uses rejection from the normal distribution, or from the uniform distribution if the interval is narrow.
+// The expected number of iterations is bounded by a small constant for all parameters.
+func (d *TruncNormal) Sample(r *Rand) float64 {
+	z := d.sampleStd(r)
+	if d.mirror {
+		z = -z
+	}
+	return math.Min(math.Max(d.mean+d.sd*z, d.lo), d.hi)
+}
+
+func (d *TruncNormal) sampleStd(r *Rand) float64 {
+	a, b := d.a, d.b
+	switch {
+	case d.tail && b-a < uniformRejectionWidth(a):
+		for {
+			z := a + (b-a)*r.Float64()
+			if r.ExpFloat64() >= (z-a)*(z+a)/2 {
+				return z
+			}
+		}
+	case d.tail:
+		alpha := (a + math.Sqrt(a*a+4)) / 2
+		for {
+			z := a + r.ExpFloat64()/alpha
+			if z <= b && r.ExpFloat64() >= (z-alpha)*(z-alpha)/2 {
+				return z
+			}
+		}
+	case b-a < math.Sqrt(2*math.Pi):
+		for {
+			z := a + (b-a)*r.Float64()
+			if r.ExpFloat64() >= z*z/2 {
+				return z
+			}
+		}
+	default:
+		for {
+			// the acceptance probability is at least Φ(√(2π)) - 1/2
+			z := r.NormFloat64()
+			if z >= a && z <= b {
+				return z
+			}
+		}
+	}
+}
+
+// cdfStd returns the probability that a mirrored standard sample is less than or equal to z.
+func (d *TruncNormal) cdfStd(z float64) float64 {
+	switch {
+	case z <= d.a:
+		return 0
+	case z >= d.b:
+		return 1
+	case d.tail:
+		return math.Expm1(logNormalTail(z)-d.la) / math.Expm1(d.lb-d.la)
+	default:
+		return normalDiff(d.a, z) / d.norm
+	}
+}
+
+// sfStd returns the probability that a mirrored standard sample is greater than z.
+func (d *TruncNormal) sfStd(z float64) float64 {
+	switch {
+	case z <= d.a:
+		return 1
+	case z >= d.b:
+		return 0
+	case d.tail:
+		lz := logNormalTail(z)
+		return math.Exp(lz-d.la) * math.Expm1(d.lb-lz) / math.Expm1(d.lb-d.la)
+	default:
+		return normalDiff(z, d.b) / d.norm
+	}
+}
+
+// PDF returns the probability density function at x.
+func (d *TruncNormal) PDF(x float64) float64 {
+	if x < d.lo || x > d.hi {
+		return 0
+	}
+	z := (x - d.mean) / d.sd
+	if d.tail {
+		return math.Exp(logNormalPDF(z)-d.la) / -math.Expm1(d.lb-d.la) / d.sd
+	}
+	return math.Exp(logNormalPDF(z)) / d.norm / d.sd
+}
+
+// CDF returns the probability that a sample is less than or equal to x.
+func (d *TruncNormal) CDF(x float64) float64 {
+	z := (x - d.mean) / d.sd
+	if d.mirror {
+		return d.sfStd(-z)
+	}
+	return d.cdfStd(z)
+}
+
+// Quantile returns the smallest x such that CDF(x) >= p. It panics if p is not in [0, 1].
+func (d *TruncNormal) Quantile(p float64) float64 {
+	checkQuantile(p)
+	if p == 0 {
+		return d.lo
+	}
+	if p == 1 {
+		return d.hi
+	}
+	guess := math.Min(math.Max(d.mean, d.lo), d.hi)
+	return invertCDF(d.CDF, p, d.lo, d.hi, guess)
+}
+
+// moments returns the mean and the variance of the mirrored standard distribution.
+//
+// The textbook formulas suffer from cancellation for narrow intervals and far in the tail,
+// where the variance is tiny compared to the squared mean, so those cases are handled separately.
+func (d *TruncNormal) moments() (float64, float64) {
+	a, b := d.a, d.b
+	w := b - a
+	switch {
+	case !math.IsInf(w, 1) && ((d.tail && a*w+w*w/2 <= truncNormalQuadratureRange) || (!d.tail && w <= 1)):
+		// integrate the density of Z - a, proportional to e^(-a*y - y*y/2), numerically
+		x, wt := gaussLegendre(truncNormalQuadratureNodes)
+		var s0, s1 float64
+		y := make([]float64, len(x))
+		g := make([]float64, len(x))
+		for i := range x {
+			y[i] = w * (1 + x[i]) / 2
+			g[i] = wt[i] * math.Exp(-a*y[i]-y[i]*y[i]/2)
+			s0 += g[i]
+			s1 += g[i] * y[i]
+		}
+		m := s1 / s0
+		s2 := 0.0
+		for i := range x {
+			s2 += g[i] * (y[i] - m) * (y[i] - m)
+		}
+		return a + m, s2 / s0
+	case d.tail && a >= truncNormalFractionBound && d.lb-d.la < -truncNormalQuadratureRange:
+		// effectively one-sided: with the Mills ratio Q(a)/φ(a) = 1/(a + t), t = 1/(a + u) and u = 2/(a + ...),
+		// the mean is a + t and the variance is t*(u - t)
+		u := 0.0
+		for k := truncNormalFractionTerms; k >= 2; k-- {
+			u = float64(k) / (a + u)
+		}
+		t := 1 / (a + u)
+		return a + t, t * (u - t)
+	}
+
+	xphi := func(x float64, l float64) float64 { // x * e^l, which is 0 for infinite x
+		if math.IsInf(x, 0) {
+			return 0
+		}
+		return x * math.Exp(l)
+	}
+	var m, s float64
+	if d.tail {
+		// φ and the normalization constant are scaled by 1/Q(a) to avoid underflow
+		norm := -math.Expm1(d.lb - d.la)
+		la, lb := logNormalPDF(a)-d.la, logNormalPDF(b)-d.la
+		m = (math.Exp(la) - math.Exp(lb)) / norm
+		s = (xphi(a, la) - xphi(b, lb)) / norm
+	} else {
+		la, lb := logNormalPDF(a), logNormalPDF(b)
+		m = (math.Exp(la) - math.Exp(lb)) / d.norm
+		s = (xphi(a, la) - xphi(b, lb)) / d.norm
+	}
+	return m, math.Max(1+s-m*m, 0)
+}
+
+// Mean returns the expected value.
+func (d *TruncNormal) Mean() float64 {
+	m, _ := d.moments()
+	if d.mirror {
+		m = -m
+	}
+	return math.Min(math.Max(d.mean+d.sd*m, d.lo), d.hi)
+}
+
+// Variance returns the variance.
+func (d *TruncNormal) Variance() float64 {
+	_, v := d.moments()
+	return d.sd * d.sd * v
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+func TestTruncNormal(t *testing.T) {
+	inf := math.Inf(1)
+	for _, c := range []struct{ mean, sd, lo, hi float64 }{
+		{0, 1, -1, 1}, {0, 1, -inf, inf}, {0, 1, -0.001, 3}, {0, 1, -3, 50}, {3, 2, -inf, 4},
+		{0, 1, 0, inf}, {0, 1, 2, inf}, {0, 1, -inf, -5}, {5, 2, 10, 10.5}, {0, 1, 0.1, 0.2},
+		{0, 1, 40, inf}, {0, 1, -100, -99.9}, {0, 1e-3, 1, 1.5}, {0, 1, 1e3, inf},
+	} {
+		d, err := rand.NewTruncNormal(c.mean, c.sd, c.lo, c.hi)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		checkContinuous(t, fmt.Sprintf("truncated normal %v", c), d)
+	}
+	for _, c := range []struct{ mean, sd, lo, hi float64 }{{0, 0, 0, 1}, {0, 1, 1, 1}, {0, 1, 2, 1}, {0, 1, math.NaN(), 1}, {math.Inf(1), 1, 0, 1}} {
+		if _, err := rand.NewTruncNormal(c.mean, c.sd, c.lo, c.hi); err == nil {
+			t.Errorf("got no error for %v", c)
+		}
+	}
+}
+
+func TestTruncNormal_Normal(t *testing.T) {
+	d, _ := rand.NewTruncNormal(1, 2, math.Inf(-1), math.Inf(1))
+	n, _ := rand.NewNormal(1, 2)
+	for _, x := range []float64{-10, -1, 0, 1, 2.5, 7} {
+		if a, b := d.CDF(x), n.CDF(x); math.Abs(a-b) > 1e-15 {
+			t.Errorf("got CDF(%v) = %v instead of %v", x, a, b)
+		}
+		if a, b := d.PDF(x), n.PDF(x); math.Abs(a-b) > 1e-14*b {
+			t.Errorf("got PDF(%v) = %v instead of %v", x, a, b)
+		}
+	}
+	if d.Mean() != 1 || math.Abs(d.Variance()-4) > 1e-12 {
+		t.Errorf("got mean %v, variance %v", d.Mean(), d.Variance())
+	}
+}
+
+func TestTruncNormal_Moments(t *testing.T) {
+	for _, c := range []struct{ lo, hi, mean, variance float64 }{
+		{-1e-4, 1e-4, 0, 4e-8 / 12 * (1 - 2e-8/15)},
+		{1e3, math.Inf(1), 1e3 + 1e-3 - 2e-9, 1e-6 - 6e-12},                   // asymptotic expansions in 1/a
+		{math.Inf(-1), -1e3, -1e3 - 1e-3 + 2e-9, 1e-6 - 6e-12},                // mirrored
+		{0, math.Inf(1), math.Sqrt(2 / math.Pi), 1 - 2/math.Pi},               // half-normal
+		{3, 3 + 1e-6, 3 + 0.5e-6 - 3*1e-12/12, 1e-12 / 12 * (1 - 9*1e-12/60)}, // nearly uniform
+	} {
+		d, _ := rand.NewTruncNormal(0, 1, c.lo, c.hi)
+		if m, v := d.Mean(), d.Variance(); math.Abs(m-c.mean) > 1e-14*math.Max(math.Abs(c.mean), 1) || math.Abs(v-c.variance) > 1e-9*c.variance {
+			t.Errorf("[%v, %v]: got mean %v, variance %v instead of %v, %v", c.lo, c.hi, m, v, c.mean, c.variance)
+		}
+	}
+}
+
+func BenchmarkTruncNormal(b *testing.B) {
+	for _, lo := range []float64{-1, 3, 100} {
+		d, _ := rand.NewTruncNormal(0, 1, lo, math.Inf(1))
+		b.Run(fmt.Sprint(lo), func(b *testing.B) {
+			r := rand.New(1)
+			for i := 0; i < b.N; i++ {
+				sinkFloat64 = d.Sample(r)
+			}
+		})
+	}
+}
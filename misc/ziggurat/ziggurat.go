@@ -0,0 +1,221 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Command ziggurat prints the ziggurat tables of NormFloat64 and ExpFloat64 as Go declarations,
+// in the format of std_normal.go and std_exp.go.
+//
+// By default, the tables are built from the constants published by Marsaglia and Tsang, which reproduces
+// the existing tables up to rounding in the last digits; with -solve, or with a non-default number of layers,
+// the constants are computed by rand.NewZiggurat.
+//
+// With -check, it instead compares the tables with the declarations in a Go file, up to checkTolerance,
+// and fails if they differ; the tables of NormFloat64 and ExpFloat64 are checked this way by go generate,
+// because replacing them would change the last digits of the values, and so the generated numbers.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kokizzu/rand"
+)
+
+const (
+	lineWidth      = 72    // maximum line length of the tables, counting the indentation tab as one character
+	checkTolerance = 1e-12 // relative difference allowed by -check, well above the rounding in the last digits
+)
+
+var (
+	normal = rand.ZigguratSpec{
+		PDF:        func(x float64) float64 { return math.Exp(-x * x / 2) },
+		InversePDF: func(y float64) float64 { return math.Sqrt(-2 * math.Log(y)) },
+		TailArea:   func(x float64) float64 { return math.Sqrt(math.Pi/2) * math.Erfc(x/math.Sqrt2) },
+		Tail:       func(r *rand.Rand, x float64) float64 { return x }, // not used for the tables
+		Symmetric:  true,
+		Layers:     256,
+		TailStart:  3.6541528853610088,
+		LayerArea:  0.00492867323399,
+	}
+	exponential = rand.ZigguratSpec{
+		PDF:        func(x float64) float64 { return math.Exp(-x) },
+		InversePDF: func(y float64) float64 { return -math.Log(y) },
+		TailArea:   func(x float64) float64 { return math.Exp(-x) },
+		Tail:       func(r *rand.Rand, x float64) float64 { return x }, // not used for the tables
+		Layers:     256,
+		TailStart:  7.69711747013104972,
+		LayerArea:  0.0039496598225815571993,
+	}
+)
+
+func main() {
+	var (
+		dist   = flag.String("dist", "normal", "distribution: normal or exp")
+		layers = flag.Int("layers", 256, "number of layers, a power of 2 between 2 and 2048")
+		solve  = flag.Bool("solve", false, "compute the tail start and the layer area instead of using the published ones")
+		check  = flag.String("check", "", "Go file to compare the tables with, instead of printing them")
+	)
+	flag.Parse()
+
+	var spec rand.ZigguratSpec
+	var suffix string
+	switch *dist {
+	case "normal":
+		spec, suffix = normal, "n"
+	case "exp":
+		spec, suffix = exponential, "e"
+	default:
+		log.Fatalf("unknown distribution %q", *dist)
+	}
+	if *solve || *layers != spec.Layers {
+		spec.TailStart, spec.LayerArea = 0, 0
+	}
+	spec.Layers = *layers
+
+	z, err := rand.NewZiggurat(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *check != "" {
+		src, err := os.ReadFile(*check)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := checkTables(*check, src, z, suffix); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if _, err := os.Stdout.WriteString(tables(z, suffix)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// tables returns the declarations of the tail start and the tables of z, with names ending in suffix.
+func tables(z *rand.Ziggurat, suffix string) string {
+	k, w, f := z.Tables()
+	var b strings.Builder
+	fmt.Fprintf(&b, "const (\n\tr%v = %v\n)\n\n", suffix, z.TailStart())
+	writeTable(&b, "k"+suffix, "uint64", len(k), func(i int) string { return fmt.Sprintf("%#x", k[i]) })
+	b.WriteString("\n")
+	writeTable(&b, "w"+suffix, "float64", len(w), func(i int) string { return fmt.Sprint(w[i]) })
+	b.WriteString("\n")
+	writeTable(&b, "f"+suffix, "float64", len(f), func(i int) string { return fmt.Sprint(f[i]) })
+	return b.String()
+}
+
+// writeTable writes the declaration of an array with n elements, filling the lines up to lineWidth.
+func writeTable(b *strings.Builder, name string, typ string, n int, elem func(i int) string) {
+	fmt.Fprintf(b, "var %v = [%v]%v{\n", name, n, typ)
+	line := "\t"
+	for i := 0; i < n; i++ {
+		e := elem(i) + ","
+		if len(line) > 1 && len(line)+1+len(e) > lineWidth {
+			b.WriteString(line + "\n")
+			line = "\t"
+		}
+		if len(line) > 1 {
+			line += " "
+		}
+		line += e
+	}
+	b.WriteString(line + "\n}\n")
+}
+
+// checkTables returns an error unless the Go source src declares the tail start and the tables of z,
+// with names ending in suffix, up to checkTolerance.
+func checkTables(filename string, src []byte, z *rand.Ziggurat, suffix string) error {
+	decls, err := parseDecls(filename, src)
+	if err != nil {
+		return err
+	}
+	k, w, f := z.Tables()
+	kf := make([]float64, len(k))
+	for i, x := range k {
+		kf[i] = float64(x)
+	}
+	for _, t := range []struct {
+		name   string
+		values []float64
+	}{
+		{"r" + suffix, []float64{z.TailStart()}},
+		{"k" + suffix, kf},
+		{"w" + suffix, w},
+		{"f" + suffix, f},
+	} {
+		name, values := t.name, t.values
+		got, ok := decls[name]
+		if !ok {
+			return fmt.Errorf("%v: no declaration of %v", filename, name)
+		}
+		if len(got) != len(values) {
+			return fmt.Errorf("%v: %v has %v values instead of %v", filename, name, len(got), len(values))
+		}
+		for i, x := range values {
+			if math.Abs(got[i]-x) > checkTolerance*math.Max(math.Abs(got[i]), math.Abs(x)) {
+				return fmt.Errorf("%v: %v[%v] is %v instead of %v", filename, name, i, got[i], x)
+			}
+		}
+	}
+	return nil
+}
+
+// parseDecls returns the values of the top-level constants and arrays declared with literals in the Go source src.
+func parseDecls(filename string, src []byte) (map[string][]float64, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	decls := map[string][]float64{}
+	for _, d := range file.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok || len(vs.Names) != 1 || len(vs.Values) != 1 {
+				continue
+			}
+			var lits []ast.Expr
+			switch v := vs.Values[0].(type) {
+			case *ast.BasicLit:
+				lits = []ast.Expr{v}
+			case *ast.CompositeLit:
+				lits = v.Elts
+			default:
+				continue
+			}
+			values := make([]float64, len(lits))
+			for i, e := range lits {
+				lit, ok := e.(*ast.BasicLit)
+				if !ok {
+					return nil, fmt.Errorf("%v: %v is not a literal at index %v", filename, vs.Names[0].Name, i)
+				}
+				if values[i], err = parseNumber(lit.Value); err != nil {
+					return nil, fmt.Errorf("%v: %v at index %v: %v", filename, vs.Names[0].Name, i, err)
+				}
+			}
+			decls[vs.Names[0].Name] = values
+		}
+	}
+	return decls, nil
+}
+
+// parseNumber parses an integer or floating-point literal.
+func parseNumber(s string) (float64, error) {
+	if u, err := strconv.ParseUint(s, 0, 64); err == nil {
+		return float64(u), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+func TestTables_Standard(t *testing.T) {
+	for _, c := range []struct {
+		filename string
+		spec     rand.ZigguratSpec
+		suffix   string
+	}{
+		{"../../std_normal.go", normal, "n"},
+		{"../../std_exp.go", exponential, "e"},
+	} {
+		z, err := rand.NewZiggurat(c.spec)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		src, err := os.ReadFile(c.filename)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		// the generated tables match the checked-in ones up to checkTolerance
+		if err := checkTables(c.filename, src, z, c.suffix); err != nil {
+			t.Errorf("checked-in tables differ from the generated ones: %v", err)
+		}
+		// and the printed tables match the generated ones
+		gen := "package rand\n\n" + tables(z, c.suffix)
+		if err := checkTables("generated", []byte(gen), z, c.suffix); err != nil {
+			t.Errorf("printed tables differ from the generated ones: %v", err)
+		}
+		bad := strings.Replace(gen, "0x0,", "0x1000,", 1)
+		if err := checkTables("modified", []byte(bad), z, c.suffix); err == nil {
+			t.Errorf("got no error for modified tables")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import "math"
+
+// maxRankedPerm is the largest n for which n! fits into an uint64.
+const maxRankedPerm = 20
+
+// CyclicPerm returns, as a slice of n ints, a uniformly distributed pseudo-random permutation
+// of the integers in the half-open interval [0, n) that consists of a single cycle: following
+// i, p[i], p[p[i]], ... visits all n integers before returning to i. It panics if n < 0.
+//
+// CyclicPerm uses the inside-out variant of Sattolo's algorithm.
+func CyclicPerm(r *Rand, n int) []int {
+	if n < 0 {
+		panic("invalid argument to CyclicPerm")
+	}
+	p := make([]int, n)
+	b := n
+	if b > math.MaxInt32 {
+		b = math.MaxInt32
+	}
+	i := 1
+	for ; i < b; i++ {
+		j := r.Uint32n(uint32(i)) // insert i into the cycle right after j
+		p[i] = p[j]
+		p[j] = i
+	}
+	for ; i < n; i++ {
+		j := r.Uint64n(uint64(i))
+		p[i] = p[j]
+		p[j] = i
+	}
+	return p
+}
+
+// Derangement returns, as a slice of n ints, a uniformly distributed pseudo-random permutation
+// of the integers in the half-open interval [0, n) without fixed points: p[i] != i for every i.
+// It panics if n < 0 or n == 1, since there are no derangements of a single element.
+//
+// Derangement rejects shuffles with fixed points as soon as one is found; on average,
+// it makes about e (2.718...) attempts.
+func Derangement(r *Rand, n int) []int {
+	if n < 0 || n == 1 {
+		panic("invalid argument to Derangement")
+	}
+	p := make([]int, n)
+retry:
+	for {
+		for i := range p {
+			p[i] = i
+		}
+		for i := n - 1; i > 0; i-- {
+			var j int
+			if i < math.MaxInt32 {
+				j = int(r.Uint32n(uint32(i) + 1))
+			} else {
+				j = int(r.Uint64n(uint64(i) + 1))
+			}
+			p[i], p[j] = p[j], p[i]
+			if p[i] == i {
+				continue retry
+			}
+		}
+		if n == 0 || p[0] != 0 {
+			return p
+		}
+	}
+}
+
+// PermRank returns the lexicographic rank of the permutation p of the integers in
+// the half-open interval [0, len(p)), in [0, len(p)!). It panics if p is not a permutation
+// or if len(p) > 20, since larger ranks do not fit into an uint64.
+func PermRank(p []int) uint64 {
+	n := len(p)
+	if n > maxRankedPerm {
+		panic("invalid argument to PermRank")
+	}
+	var seen uint32
+	var rank uint64
+	for i, v := range p {
+		if v < 0 || v >= n || seen&(1<<v) != 0 {
+			panic("invalid argument to PermRank")
+		}
+		seen |= 1 << v
+		c := 0 // number of smaller elements to the right of v (digit of the Lehmer code)
+		for _, w := range p[i+1:] {
+			if w < v {
+				c++
+			}
+		}
+		rank = rank*uint64(n-i) + uint64(c)
+	}
+	return rank
+}
+
+// PermUnrank returns, as a slice of n ints, the permutation of the integers in the half-open
+// interval [0, n) with the lexicographic rank rank. It is the inverse of [PermRank].
+// PermUnrank panics if n < 0, n > 20 or rank >= n!.
+func PermUnrank(rank uint64, n int) []int {
+	if n < 0 || n > maxRankedPerm {
+		panic("invalid argument to PermUnrank")
+	}
+	f := uint64(1)
+	for i := 2; i <= n; i++ {
+		f *= uint64(i)
+	}
+	if rank >= f {
+		panic("invalid argument to PermUnrank")
+	}
+	free := make([]int, n)
+	for i := range free {
+		free[i] = i
+	}
+	p := make([]int, n)
+	for i := range p {
+		f /= uint64(n - i)
+		c := int(rank / f)
+		rank %= f
+		p[i] = free[c]
+		free = append(free[:c], free[c+1:]...)
+	}
+	return p
+}
@@ -0,0 +1,174 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"sort"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/kokizzu/rand"
+)
+
+func BenchmarkCyclicPerm(b *testing.B) {
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		rand.CyclicPerm(r, small)
+	}
+}
+
+func BenchmarkDerangement(b *testing.B) {
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		rand.Derangement(r, small)
+	}
+}
+
+func checkPerm(t *rapid.T, p []int, n int) {
+	if len(p) != n {
+		t.Fatalf("got %v elements instead of %v", len(p), n)
+	}
+	c := append([]int(nil), p...)
+	sort.Ints(c)
+	for i, v := range c {
+		if v != i {
+			t.Fatalf("not a permutation: %v", p)
+		}
+	}
+}
+
+func TestCyclicPerm(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		r := rand.New(rapid.Uint64().Draw(t, "seed").(uint64))
+		n := rapid.IntRange(0, small).Draw(t, "n").(int)
+		p := rand.CyclicPerm(r, n)
+		checkPerm(t, p, n)
+		if n == 0 {
+			return
+		}
+		i, l := p[0], 1
+		for ; i != 0; i = p[i] {
+			l++
+		}
+		if l != n {
+			t.Fatalf("cycle of length %v instead of %v: %v", l, n, p)
+		}
+	})
+}
+
+func TestDerangement(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		r := rand.New(rapid.Uint64().Draw(t, "seed").(uint64))
+		n := rapid.IntRange(0, small).Filter(func(n int) bool { return n != 1 }).Draw(t, "n").(int)
+		p := rand.Derangement(r, n)
+		checkPerm(t, p, n)
+		for i, v := range p {
+			if v == i {
+				t.Fatalf("fixed point %v in %v", i, p)
+			}
+		}
+	})
+}
+
+func testPermUniformity(t *testing.T, gen func(r *rand.Rand, n int) []int, n int, valid func(p []int) bool) {
+	const samples = 1 << 16
+	r := rand.New(1)
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+	counts := make([]int, f)
+	for i := 0; i < samples; i++ {
+		counts[rand.PermRank(gen(r, n))]++
+	}
+	var ranks []uint64
+	for i := 0; i < f; i++ {
+		if valid(rand.PermUnrank(uint64(i), n)) {
+			ranks = append(ranks, uint64(i))
+		}
+	}
+	expected := make([]float64, f)
+	for _, i := range ranks {
+		expected[i] = float64(samples) / float64(len(ranks))
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestCyclicPerm_Uniformity(t *testing.T) {
+	testPermUniformity(t, rand.CyclicPerm, 5, func(p []int) bool {
+		l := 1
+		for i := p[0]; i != 0; i = p[i] {
+			l++
+		}
+		return l == len(p)
+	})
+}
+
+func TestDerangement_Uniformity(t *testing.T) {
+	testPermUniformity(t, rand.Derangement, 5, func(p []int) bool {
+		for i, v := range p {
+			if v == i {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestPermRank(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		r := rand.New(rapid.Uint64().Draw(t, "seed").(uint64))
+		n := rapid.IntRange(0, 20).Draw(t, "n").(int)
+		p := r.Perm(n)
+		rank := rand.PermRank(p)
+		q := rand.PermUnrank(rank, n)
+		checkPerm(t, q, n)
+		for i := range p {
+			if p[i] != q[i] {
+				t.Fatalf("PermUnrank(PermRank(%v)) = %v", p, q)
+			}
+		}
+	})
+}
+
+func TestPermRank_Lexicographic(t *testing.T) {
+	const n = 5
+	var prev []int
+	for i := uint64(0); i < 120; i++ {
+		p := rand.PermUnrank(i, n)
+		if rand.PermRank(p) != i {
+			t.Fatalf("PermRank(%v) = %v instead of %v", p, rand.PermRank(p), i)
+		}
+		if prev != nil {
+			j := 0
+			for j < n && prev[j] == p[j] {
+				j++
+			}
+			if j == n || prev[j] > p[j] {
+				t.Fatalf("%v does not follow %v", p, prev)
+			}
+		}
+		prev = p
+	}
+	if p := rand.PermUnrank(2432902008176639999, 20); p[0] != 19 || p[19] != 0 {
+		t.Fatalf("last permutation of 20 elements is %v", p)
+	}
+}
+
+func TestPermRank_Invalid(t *testing.T) {
+	for _, p := range [][]int{{1}, {0, 0}, {-1, 0}, make([]int, 21)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("got no panic for %v", p)
+				}
+			}()
+			rand.PermRank(p)
+		}()
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/kokizzu/rand"
+)
+
+func BenchmarkPermutation_At(b *testing.B) {
+	p := rand.NewPermutation(rand.New(1), 1<<40)
+	var s uint64
+	for i := 0; i < b.N; i++ {
+		s = p.At(uint64(i))
+	}
+	sinkUint64 = s
+}
+
+func TestPermutation(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.Uint64Range(0, 1000).Draw(t, "n").(uint64)
+		p := rand.NewPermutation(rand.New(rapid.Uint64().Draw(t, "seed").(uint64)), n)
+		if p.Len() != n {
+			t.Fatalf("got length %v instead of %v", p.Len(), n)
+		}
+		seen := make([]bool, n)
+		it := p.Iter()
+		for i := uint64(0); ; i++ {
+			v, ok := it.Next()
+			if !ok {
+				if i != n {
+					t.Fatalf("iterator stopped after %v elements instead of %v", i, n)
+				}
+				break
+			}
+			if v >= n || seen[v] {
+				t.Fatalf("got invalid or repeated element %v at position %v", v, i)
+			}
+			seen[v] = true
+			if v != p.At(i) || p.Index(v) != i {
+				t.Fatalf("At(%v) = %v, Index(%v) = %v, iterator returned %v", i, p.At(i), v, p.Index(v), v)
+			}
+		}
+	})
+}
+
+func TestPermutation_Large(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.Uint64Min(1).Draw(t, "n").(uint64)
+		if rapid.Bool().Draw(t, "max").(bool) {
+			n = math.MaxUint64
+		}
+		p := rand.NewPermutation(rand.New(rapid.Uint64().Draw(t, "seed").(uint64)), n)
+		i := rapid.Uint64Range(0, n-1).Draw(t, "i").(uint64)
+		v := p.At(i)
+		if v >= n || p.Index(v) != i {
+			t.Fatalf("At(%v) = %v, Index(%v) = %v", i, v, v, p.Index(v))
+		}
+	})
+}
+
+func TestPermutation_Distribution(t *testing.T) {
+	const trials = 1 << 16
+	r := rand.New(1)
+	for _, n := range []uint64{2, 3, 5, 9, 17} {
+		counts := make([]int, n*n)
+		for i := 0; i < trials; i++ {
+			p := rand.NewPermutation(r, n)
+			counts[p.At(0)*n+p.At(1)]++
+		}
+		expected := make([]float64, n*n)
+		for i := uint64(0); i < n; i++ {
+			for j := uint64(0); j < n; j++ {
+				if i != j {
+					expected[i*n+j] = trials / float64(n*(n-1))
+				}
+			}
+		}
+		checkChiSquared(t, counts, expected)
+	}
+}
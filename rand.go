@@ -15,8 +15,10 @@ package rand
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"math/bits"
 )
 
@@ -30,7 +32,11 @@ const (
 	f24Mul = 0x1.0p-24
 	f53Mul = 0x1.0p-53
 
-	randSizeof = 8*4 + 8 + 1
+	randSizeof     = 8*4 + 8 + 1
+	randBitsSizeof = randSizeof + 8 + 1 // with non-empty bit cache
+
+	bernoulliWordBits      = 12 // bits of p used by FillBernoulliBits to combine words
+	bernoulliGeometricCost = 12 // cost of a geometric distance relative to a word, for FillBernoulliBits
 )
 
 var (
@@ -46,8 +52,10 @@ var (
 // [SFC64]: http://pracrand.sourceforge.net/RNG_engines.txt
 type Rand struct {
 	sfc64
-	val uint64
-	pos int
+	val   uint64
+	pos   int
+	bits  uint64 // bit cache used by Bool, Bits and Bernoulli: nbits most significant bits, the rest are zero
+	nbits int
 }
 
 // New returns an initialized generator. If seed is empty, generator is initialized to a non-deterministic state.
@@ -95,12 +103,20 @@ func (r *Rand) Seed(seed uint64) {
 	r.init1(seed)
 	r.val = 0
 	r.pos = 0
+	r.bits = 0
+	r.nbits = 0
 }
 
 // MarshalBinary returns the binary representation of the current state of the generator.
 func (r *Rand) MarshalBinary() ([]byte, error) {
-	var data [randSizeof]byte
-	r.marshalBinary(&data)
+	var data [randBitsSizeof]byte
+	r.marshalBinary((*[randSizeof]byte)(data[:randSizeof]))
+	if r.nbits == 0 {
+		// same representation as before the bit cache was introduced
+		return data[:randSizeof], nil
+	}
+	binary.LittleEndian.PutUint64(data[randSizeof:], r.bits)
+	data[randSizeof+8] = byte(r.nbits)
 	return data[:], nil
 }
 
@@ -114,29 +130,322 @@ func (r *Rand) marshalBinary(data *[randSizeof]byte) {
 }
 
 // UnmarshalBinary sets the state of the generator to the state represented in data.
+// It returns an error, leaving the generator unchanged, if data is truncated or has an invalid bit cache.
 func (r *Rand) UnmarshalBinary(data []byte) error {
-	if len(data) < randSizeof {
+	if len(data) < randSizeof || (len(data) > randSizeof && len(data) < randBitsSizeof) {
 		return io.ErrUnexpectedEOF
 	}
+	var bits uint64
+	var nbits int
+	if len(data) >= randBitsSizeof {
+		bits = binary.LittleEndian.Uint64(data[randSizeof:])
+		nbits = int(data[randSizeof+8])
+		// the cached bits are the nbits most significant ones, the rest are zero
+		if nbits > 64 || bits&(math.MaxUint64>>nbits) != 0 {
+			return fmt.Errorf("rand: invalid bit cache %#x with %v bits", bits, nbits)
+		}
+	}
 	r.a = binary.LittleEndian.Uint64(data[0:])
 	r.b = binary.LittleEndian.Uint64(data[8:])
 	r.c = binary.LittleEndian.Uint64(data[16:])
 	r.w = binary.LittleEndian.Uint64(data[24:])
 	r.val = binary.LittleEndian.Uint64(data[32:])
 	r.pos = int(data[40])
+	r.bits = bits
+	r.nbits = nbits
 	return nil
 }
 
+// Bernoulli returns true with probability p. It panics if p is not in the closed interval [0.0, 1.0].
+//
+// Bernoulli compares the bits of a uniformly distributed number with the exact binary expansion of p,
+// stopping at the first difference; on average, it uses 2 bits from the same cache as [Rand.Bool].
+func (r *Rand) Bernoulli(p float64) bool {
+	if !(p >= 0 && p <= 1) {
+		panic("invalid argument to Bernoulli")
+	}
+	if p == 1 {
+		return true
+	}
+	if r.nbits == 0 {
+		r.bits, r.nbits = r.next64(), 64
+	}
+	// fast path: compare the cached bits with the first 63 bits of p's expansion
+	// (truncation keeps them exact), leaving the rest of the expansion to the loop below
+	q := uint64(int64(p*0x1p63)) << 1
+	if d := (r.bits ^ q) & (math.MaxUint64 << (64 - r.nbits)) &^ 1; d != 0 {
+		return r.bernoulliBit(q, d)
+	}
+	for p != 0 {
+		if r.nbits == 0 {
+			r.bits, r.nbits = r.next64(), 64
+		}
+		// next nbits bits of p's expansion; scaling by a power of two and taking the integer part are exact
+		x := math.Ldexp(p, r.nbits)
+		hi := math.Floor(x)
+		q := uint64(hi) << (64 - r.nbits)
+		if d := r.bits ^ q; d != 0 {
+			return r.bernoulliBit(q, d)
+		}
+		r.bits, r.nbits = 0, 0
+		p = x - hi
+	}
+	return false
+}
+
+// bernoulliBit consumes the cached bits up to the first one that differs from the expansion q,
+// and reports whether the random bit is the smaller one.
+func (r *Rand) bernoulliBit(q uint64, d uint64) bool {
+	k := bits.LeadingZeros64(d)
+	r.bits <<= k + 1
+	r.nbits -= k + 1
+	return q&(1<<(63-k)) != 0
+}
+
+// BigInt returns, as a new *big.Int, a uniformly distributed pseudo-random number in [0, max).
+// It panics if max <= 0. The result depends only on the state of r and the value of max,
+// not on the platform word size.
+func (r *Rand) BigInt(max *big.Int) *big.Int {
+	if max.Sign() <= 0 {
+		panic("invalid argument to BigInt")
+	}
+	if max.IsUint64() {
+		return new(big.Int).SetUint64(r.Uint64n(max.Uint64()))
+	}
+	// multi-word version of the algorithm used by Uint64n: the high words of max*u are the result,
+	// and the high words of max*v for one more random word v can carry into them from the low words
+	k := uint((max.BitLen() + 63) / 64 * 64)
+	prod := new(big.Int).Mul(max, r.bigIntWords(int(k/64)))
+	res := new(big.Int).Rsh(prod, k)
+	frac := prod.Sub(prod, new(big.Int).Lsh(res, k))
+	t := new(big.Int).Mul(max, new(big.Int).SetUint64(r.next64()))
+	frac.Add(frac, t.Rsh(t, 64))
+	if uint(frac.BitLen()) > k {
+		res.Add(res, big.NewInt(1))
+	}
+	return res
+}
+
+// BigIntBits returns, as a new *big.Int, a uniformly distributed pseudo-random number in [0, 2^n).
+// It panics if n < 0. The result depends only on the state of r and n, not on the platform word size.
+func (r *Rand) BigIntBits(n int) *big.Int {
+	if n < 0 {
+		panic("invalid argument to BigIntBits")
+	}
+	if n%64 == 0 {
+		return r.bigIntWords(n / 64)
+	}
+	x := r.bigIntWords(n/64 + 1)
+	return x.Rsh(x, uint(64-n%64))
+}
+
+// bigIntWords returns a number made of w random words, with the first one being the most significant.
+func (r *Rand) bigIntWords(w int) *big.Int {
+	buf := make([]byte, 8*w)
+	for i := 0; i < w; i++ {
+		binary.BigEndian.PutUint64(buf[8*i:], r.next64())
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+// Bits returns k uniformly distributed pseudo-random bits in the low bits of an uint64. It panics if k < 0 or k > 64.
+// Bits takes bits from the same cache as [Rand.Bool], so successive calls with small k rarely advance the generator.
+// The result is the same as of k calls to [Rand.Bool], with the first one being the most significant bit.
+func (r *Rand) Bits(k int) uint64 {
+	if k < 0 || k > 64 {
+		panic("invalid argument to Bits")
+	}
+	if k <= r.nbits {
+		v := r.bits >> (64 - k)
+		r.bits <<= k
+		r.nbits -= k
+		return v
+	}
+	n := r.nbits
+	x := r.next64()
+	v := r.bits>>(64-k) | x>>(64-(k-n))
+	r.bits = x << (k - n)
+	r.nbits = 64 - (k - n)
+	return v
+}
+
+// Bool returns a uniformly distributed pseudo-random bool. Bool takes one bit from a cache
+// that is refilled every 64 calls, so it advances the generator 64 times less often than [Rand.Uint64].
+func (r *Rand) Bool() bool {
+	if r.nbits == 0 {
+		r.bits, r.nbits = r.next64(), 64
+	}
+	b := int64(r.bits) < 0
+	r.bits <<= 1
+	r.nbits--
+	return b
+}
+
+// FillBernoulliBits sets every bit of dst independently to 1 with probability p, and to 0 otherwise.
+// It panics if p is not in the closed interval [0.0, 1.0].
+//
+// FillBernoulliBits combines raw words with AND or OR according to the first 12 bits of the binary
+// expansion of p, using one word per significant bit for every 64 bits of dst (e.g. a single word for p = 0.5);
+// the rare bits whose outcome depends on the rest of the expansion are decided by [Rand.Bernoulli],
+// so the probability is exact. When few bits are set (or cleared), it is cheaper to set (or clear) them
+// at geometrically distributed distances, like [Rand.SparseBits].
+func (r *Rand) FillBernoulliBits(dst []uint64, p float64) {
+	if !(p >= 0 && p <= 1) {
+		panic("invalid argument to FillBernoulliBits")
+	}
+	r.fillBernoulliBits(dst, p, bernoulliWordBits)
+}
+
+// fillBernoulliBits is FillBernoulliBits using the first wordBits bits of p to combine words.
+func (r *Rand) fillBernoulliBits(dst []uint64, p float64, wordBits int) {
+	m := uint32(p * float64(uint32(1)<<wordBits))
+	tz := bits.TrailingZeros32(m)
+	k := wordBits - tz // number of words combined for every word of dst
+	if m == 0 || m >= 1<<wordBits || 64*math.Min(p, 1-p)*bernoulliGeometricCost < float64(k) {
+		var fill uint64
+		q := p
+		if p > 0.5 {
+			fill, q = math.MaxUint64, 1-p
+		}
+		for i := range dst {
+			dst[i] = fill
+		}
+		for i := r.geometric(q, len(dst)*64); i < len(dst)*64; i += 1 + r.geometric(q, len(dst)*64-i-1) {
+			dst[i/64] ^= 1 << (i % 64)
+		}
+		return
+	}
+	m >>= tz
+	rem := p*float64(uint64(1)<<k) - float64(m) // exact, in [0, 1)
+	for i := range dst {
+		// after processing bits 0..j of m, the complemented words form a uniform (j+1)-bit number in every lane;
+		// w is set where it is less than bits 0..j of m, and eq where it is equal to them
+		var w uint64
+		eq := uint64(math.MaxUint64)
+		for j := 0; j < k; j++ {
+			if x := r.next64(); m>>j&1 != 0 {
+				w |= x
+				eq &^= x
+			} else {
+				w &= x
+				eq &= x
+			}
+		}
+		if rem != 0 {
+			for ; eq != 0; eq &= eq - 1 {
+				if r.Bernoulli(rem) {
+					w |= eq & -eq
+				}
+			}
+		}
+		dst[i] = w
+	}
+}
+
 // Float32 returns, as a float32, a uniformly distributed pseudo-random number in the half-open interval [0.0, 1.0).
 func (r *Rand) Float32() float32 {
 	return float32(r.next32()&int24Mask) * f24Mul
 }
 
+// Float32Closed returns, as a float32, a uniformly distributed pseudo-random number in the closed interval [0.0, 1.0].
+func (r *Rand) Float32Closed() float32 {
+	return float32(r.Uint32n(1<<24+1)) * f24Mul
+}
+
+// Float32Full returns, as a float32, a pseudo-random number in the half-open interval [0.0, 1.0).
+// Unlike [Rand.Float32], which only returns multiples of 2^-24, Float32Full can return every
+// representable float32 in [0.0, 1.0), each with probability equal to the distance to the next one.
+func (r *Rand) Float32Full() float32 {
+	// same algorithm as Float64Full, with 23-bit mantissa and 8-bit exponent
+	u := r.next64()
+	z := 0
+	for u == 0 {
+		z += 64
+		if z >= 149 {
+			return 0
+		}
+		u = r.next64()
+	}
+	lz := bits.LeadingZeros64(u)
+	z += lz
+	m := u << (lz + 1)
+	if lz > 40 {
+		m |= r.next64() >> (63 - lz)
+	}
+	mant := uint32(m >> 41)
+	if z < 126 {
+		return math.Float32frombits(uint32(126-z)<<23 | mant)
+	}
+	return math.Float32frombits((1<<23 | mant) >> (z - 125))
+}
+
+// Float32Open returns, as a float32, a uniformly distributed pseudo-random number in the open interval (0.0, 1.0).
+func (r *Rand) Float32Open() float32 {
+	for {
+		if v := r.next32() & int24Mask; v != 0 {
+			return float32(v) * f24Mul
+		}
+	}
+}
+
+// Float32OpenClosed returns, as a float32, a uniformly distributed pseudo-random number in the half-open interval (0.0, 1.0].
+func (r *Rand) Float32OpenClosed() float32 {
+	return float32(r.next32()&int24Mask+1) * f24Mul
+}
+
 // Float64 returns, as a float64, a uniformly distributed pseudo-random number in the half-open interval [0.0, 1.0).
 func (r *Rand) Float64() float64 {
 	return float64(r.next64()&int53Mask) * f53Mul
 }
 
+// Float64Closed returns, as a float64, a uniformly distributed pseudo-random number in the closed interval [0.0, 1.0].
+func (r *Rand) Float64Closed() float64 {
+	return float64(r.Uint64n(1<<53+1)) * f53Mul
+}
+
+// Float64Full returns, as a float64, a pseudo-random number in the half-open interval [0.0, 1.0).
+// Unlike [Rand.Float64], which only returns multiples of 2^-53, Float64Full can return every
+// representable float64 in [0.0, 1.0), each with probability equal to the distance to the next one.
+// This makes it suitable for inverse transform sampling, where values close to 0 determine the tail.
+func (r *Rand) Float64Full() float64 {
+	// conceptually, we generate an infinite binary fraction 0.b1b2b3... and round it down:
+	// the number of leading zero bits determines the exponent, the following 52 bits the mantissa
+	u := r.next64()
+	z := 0
+	for u == 0 {
+		z += 64
+		if z >= 1074 {
+			return 0
+		}
+		u = r.next64()
+	}
+	lz := bits.LeadingZeros64(u)
+	z += lz
+	m := u << (lz + 1)
+	if lz > 11 {
+		m |= r.next64() >> (63 - lz)
+	}
+	mant := m >> 12
+	if z < 1022 {
+		return math.Float64frombits(uint64(1022-z)<<52 | mant)
+	}
+	return math.Float64frombits((1<<52 | mant) >> (z - 1021)) // subnormal
+}
+
+// Float64Open returns, as a float64, a uniformly distributed pseudo-random number in the open interval (0.0, 1.0).
+func (r *Rand) Float64Open() float64 {
+	for {
+		if v := r.next64() & int53Mask; v != 0 {
+			return float64(v) * f53Mul
+		}
+	}
+}
+
+// Float64OpenClosed returns, as a float64, a uniformly distributed pseudo-random number in the half-open interval (0.0, 1.0].
+func (r *Rand) Float64OpenClosed() float64 {
+	return float64(r.next64()&int53Mask+1) * f53Mul
+}
+
 // Int returns a uniformly distributed non-negative pseudo-random int.
 func (r *Rand) Int() int {
 	return int(r.next64() & intMask)
@@ -183,6 +492,22 @@ func (r *Rand) Intn(n int) int {
 	}
 }
 
+// NormComplex128 returns a circularly-symmetric complex normal pseudo-random number with mean 0
+// and E[|z|^2] = 1: its real and imaginary parts are independent, normally distributed with variance 1/2.
+func (r *Rand) NormComplex128() complex128 {
+	re := r.NormFloat64()
+	im := r.NormFloat64()
+	return complex(re*(math.Sqrt2/2), im*(math.Sqrt2/2))
+}
+
+// NormFloat64s fills dst with independent standard normal pseudo-random numbers,
+// the same ones that len(dst) calls to [Rand.NormFloat64] would return.
+func (r *Rand) NormFloat64s(dst []float64) {
+	for i := range dst {
+		dst[i] = r.NormFloat64()
+	}
+}
+
 // Perm returns, as a slice of n ints, a pseudo-random permutation of the integers in the half-open interval [0, n).
 func (r *Rand) Perm(n int) []int {
 	p := make([]int, n)
@@ -250,6 +575,71 @@ func (r *Rand) Shuffle(n int, swap func(i, j int)) {
 	}
 }
 
+// SparseBits appends to dst, in increasing order, the indices of n bits that are independently set
+// to 1 with probability p, and returns the extended slice. It panics if n < 0 or if p is not in
+// the closed interval [0.0, 1.0]. SparseBits takes O(1 + n*p) time, since it skips
+// over geometrically distributed runs of unset bits.
+func (r *Rand) SparseBits(dst []int, n int, p float64) []int {
+	if n < 0 || !(p >= 0 && p <= 1) {
+		panic("invalid argument to SparseBits")
+	}
+	for i := r.geometric(p, n); i < n; i += 1 + r.geometric(p, n-i-1) {
+		dst = append(dst, i)
+	}
+	return dst
+}
+
+// geometric returns the number of failures before the first success in independent trials
+// with success probability p, or max if it is greater than max.
+func (r *Rand) geometric(p float64, max int) int {
+	g := math.Floor(r.ExpFloat64() / -math.Log1p(-p))
+	if g < float64(max) && int(g) < max {
+		return int(g)
+	}
+	return max
+}
+
+// Uint128 returns a uniformly distributed pseudo-random 128-bit value as two uint64 halves.
+func (r *Rand) Uint128() (hi uint64, lo uint64) {
+	return r.next64(), r.next64()
+}
+
+// Uint128n returns, as two uint64 halves, a uniformly distributed pseudo-random number in [0, n),
+// where n = hi*2^64 + lo. Uint128n(0, 0) returns (0, 0).
+func (r *Rand) Uint128n(hi uint64, lo uint64) (uint64, uint64) {
+	if hi == 0 {
+		return 0, r.Uint64n(lo)
+	}
+	uh, ul := r.next64(), r.next64()
+	return uint128n(hi, lo, uh, ul, r.next64())
+}
+
+// uint128n is the 128-bit version of the algorithm used by Uint64n: it returns the high 128 bits
+// of the 320-bit product of n = hi:lo and the 192-bit uniform number uh:ul:v.
+func uint128n(hi uint64, lo uint64, uh uint64, ul uint64, v uint64) (uint64, uint64) {
+	rh, rl, fh, fl := mul128(hi, lo, uh, ul)
+	h1, _ := bits.Mul64(lo, v)
+	h2, l2 := bits.Mul64(hi, v)
+	tl, c1 := bits.Add64(l2, h1, 0) // (h2+c1):tl are the high 128 bits of n*v
+	_, c2 := bits.Add64(fl, tl, 0)
+	_, c3 := bits.Add64(fh, h2+c1, c2)
+	rl, c := bits.Add64(rl, 0, c3)
+	return rh + c, rl
+}
+
+// mul128 returns the 256-bit product of ah:al and bh:bl as four words, the most significant first.
+func mul128(ah uint64, al uint64, bh uint64, bl uint64) (uint64, uint64, uint64, uint64) {
+	h00, l00 := bits.Mul64(al, bl)
+	h01, l01 := bits.Mul64(al, bh)
+	h10, l10 := bits.Mul64(ah, bl)
+	h11, l11 := bits.Mul64(ah, bh)
+	w1, c1 := bits.Add64(h00, l01, 0)
+	w1, c2 := bits.Add64(w1, l10, 0)
+	w2, c3 := bits.Add64(h01, h10, c1)
+	w2, c4 := bits.Add64(w2, l11, c2)
+	return h11 + c3 + c4, w2, w1, l00
+}
+
 // Uint32 returns a uniformly distributed pseudo-random 32-bit value as an uint32.
 func (r *Rand) Uint32() uint32 {
 	return uint32(r.next32())
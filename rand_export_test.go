@@ -8,6 +8,18 @@ func Int31nForTest(r *Rand, n int32) int32 {
 	return r.Int31n(n)
 }
 
+func Uint128nForTest(hi uint64, lo uint64, uh uint64, ul uint64, v uint64) (uint64, uint64) {
+	return uint128n(hi, lo, uh, ul, v)
+}
+
+func BinomialLogRatioForTest(n float64, m float64, y float64, r float64, q float64) float64 {
+	return binomialLogRatio(n, m, y, r, q)
+}
+
+func FillBernoulliBitsForTest(r *Rand, dst []uint64, p float64, wordBits int) {
+	r.fillBernoulliBits(dst, p, wordBits)
+}
+
 func GetNormalDistributionParameters() (float64, [256]uint64, [256]float64, [256]float64) {
 	return rn, kn, wn, fn
 }
@@ -8,7 +8,194 @@
 
 package rand
 
-import "math"
+import (
+	"math"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+const parallelShuffleMaxWorkers = 256 // the bucket offsets of ParallelShuffle take workers² words
+
+// Between returns a uniformly distributed pseudo-random integer in the closed interval [lo, hi].
+// It panics if lo > hi. Any interval representable by T is supported, including the full
+// range of T, e.g. Between(r, math.MinInt64, math.MaxInt64).
+func Between[T constraints.Integer](r *Rand, lo T, hi T) T {
+	if lo > hi {
+		panic("invalid argument to Between")
+	}
+	// modular uint64 arithmetic gives the correct width for both signed and unsigned T
+	n := uint64(hi) - uint64(lo) + 1
+	if n == 0 {
+		return T(r.Uint64())
+	}
+	return T(uint64(lo) + r.Uint64n(n))
+}
+
+// Choice returns a uniformly distributed pseudo-random element of s. It panics if s is empty.
+func Choice[S ~[]E, E any](r *Rand, s S) E {
+	if len(s) == 0 {
+		panic("invalid argument to Choice")
+	}
+	return s[r.Uint64n(uint64(len(s)))]
+}
+
+// Duration returns a uniformly distributed pseudo-random duration in the closed interval [lo, hi].
+// It panics if lo > hi.
+func Duration(r *Rand, lo time.Duration, hi time.Duration) time.Duration {
+	if lo > hi {
+		panic("invalid argument to Duration")
+	}
+	return Between(r, lo, hi)
+}
+
+// LogUniform returns a pseudo-random number in the half-open interval [lo, hi),
+// whose logarithm is uniformly distributed. It panics if lo <= 0, lo >= hi or hi is not finite.
+func LogUniform[T constraints.Float](r *Rand, lo T, hi T) T {
+	l, h := float64(lo), float64(hi)
+	if !(l > 0 && l < h) || math.IsInf(h, 0) {
+		panic("invalid argument to LogUniform")
+	}
+	return clampFloat(T(math.Exp(lerp(math.Log(l), math.Log(h), r.Float64()))), lo, hi)
+}
+
+// ParallelShuffle pseudo-randomizes the order of the elements of s, like [Shuffle],
+// using up to workers goroutines, but no more than 256. The result is deterministic for a given state of r
+// and number of workers, but differs from the result of [Shuffle].
+// ParallelShuffle panics if workers < 1.
+//
+// ParallelShuffle scatters elements to workers buckets chosen uniformly at random, then shuffles every bucket
+// with Fisher-Yates; this produces a uniformly random permutation. It uses an extra buffer of len(s) elements,
+// and for small slices it is slower than [Shuffle] because of the goroutine overhead.
+func ParallelShuffle[S ~[]E, E any](r *Rand, s S, workers int) {
+	if workers < 1 {
+		panic("invalid argument to ParallelShuffle")
+	}
+	if workers > parallelShuffleMaxWorkers {
+		workers = parallelShuffleMaxWorkers
+	}
+	if workers > len(s) {
+		workers = len(s)
+	}
+	if workers <= 1 {
+		Shuffle(r, s)
+		return
+	}
+
+	rs := make([]Rand, workers)
+	for i := range rs {
+		rs[i].init3(r.next64(), r.next64(), r.next64())
+	}
+	chunk := func(w int) (int, int) {
+		return int(uint64(len(s)) * uint64(w) / uint64(workers)), int(uint64(len(s)) * uint64(w+1) / uint64(workers))
+	}
+	parallel := func(f func(w int)) {
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func(w int) {
+				defer wg.Done()
+				f(w)
+			}(w)
+		}
+		wg.Wait()
+	}
+
+	// offsets[w*workers+b] is where worker w puts elements of bucket b;
+	// buckets are laid out one after another, and inside a bucket workers are laid out in order
+	offsets := make([]int, workers*workers)
+	parallel(func(w int) {
+		g := rs[w] // copy, to replay the same bucket choices when scattering
+		lo, hi := chunk(w)
+		counts := make([]int, workers)
+		for i := lo; i < hi; i++ {
+			counts[g.Uint64n(uint64(workers))]++
+		}
+		copy(offsets[w*workers:], counts)
+	})
+	starts := make([]int, workers+1)
+	n := 0
+	for b := 0; b < workers; b++ {
+		starts[b] = n
+		for w := 0; w < workers; w++ {
+			c := offsets[w*workers+b]
+			offsets[w*workers+b] = n
+			n += c
+		}
+	}
+	starts[workers] = n
+
+	buf := make(S, len(s))
+	parallel(func(w int) {
+		g := &rs[w]
+		lo, hi := chunk(w)
+		o := append([]int(nil), offsets[w*workers:(w+1)*workers]...)
+		for i := lo; i < hi; i++ {
+			b := g.Uint64n(uint64(workers))
+			buf[o[b]] = s[i]
+			o[b]++
+		}
+	})
+	parallel(func(b int) {
+		t := buf[starts[b]:starts[b+1]]
+		Shuffle(&rs[b], t)
+		copy(s[starts[b]:], t)
+	})
+}
+
+// PartialShuffle pseudo-randomizes the order of the elements of s, so that s[:k] is a uniformly distributed
+// sample of k elements of s in pseudo-random order. The order of elements in s[k:] is unspecified.
+// PartialShuffle panics if k < 0 or k > len(s).
+//
+// PartialShuffle does O(k) work, so it is much faster than shuffling all of s when k is small.
+func PartialShuffle[S ~[]E, E any](r *Rand, s S, k int) {
+	if k < 0 || k > len(s) {
+		panic("invalid argument to PartialShuffle")
+	}
+	n := len(s)
+	for i := 0; i < k; i++ {
+		j := i + int(r.Uint64n(uint64(n-i)))
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// SampleIndices returns, as a slice of k ints, a pseudo-random sample of distinct integers
+// in the half-open interval [0, n), in pseudo-random order. It panics if k < 0 or k > n.
+func SampleIndices(r *Rand, n int, k int) []int {
+	if k < 0 || k > n {
+		panic("invalid argument to SampleIndices")
+	}
+	if !useFloyd(n, k) {
+		p := make([]int, n)
+		for i := range p {
+			p[i] = i
+		}
+		PartialShuffle(r, p, k)
+		return p[:k:k]
+	}
+	return floydSample(r, n, k)
+}
+
+// SampleK returns a new slice of k pseudo-randomly chosen elements of s, in pseudo-random order.
+// Every element of s is chosen at most once (duplicate values in s can be chosen once per occurrence).
+// SampleK panics if k < 0 or k > len(s).
+func SampleK[S ~[]E, E any](r *Rand, s S, k int) S {
+	if k < 0 || k > len(s) {
+		panic("invalid argument to SampleK")
+	}
+	if !useFloyd(len(s), k) {
+		c := append(S(nil), s...)
+		PartialShuffle(r, c, k)
+		return c[:k:k]
+	}
+	c := make(S, k)
+	for i, j := range floydSample(r, len(s), k) {
+		c[i] = s[j]
+	}
+	return c
+}
 
 // Shuffle pseudo-randomizes the order of the elements of s.
 func Shuffle[S ~[]E, E any](r *Rand, s S) {
@@ -22,3 +209,58 @@ func Shuffle[S ~[]E, E any](r *Rand, s S) {
 		s[i], s[j] = s[j], s[i]
 	}
 }
+
+// Uniform returns a uniformly distributed pseudo-random number in the half-open interval [lo, hi).
+// It panics if lo >= hi or if either of lo and hi is not finite.
+func Uniform[T constraints.Float](r *Rand, lo T, hi T) T {
+	l, h := float64(lo), float64(hi)
+	if !(l < h) || math.IsInf(l, 0) || math.IsInf(h, 0) {
+		panic("invalid argument to Uniform")
+	}
+	return clampFloat(T(lerp(l, h, r.Float64())), lo, hi)
+}
+
+// lerp maps u from [0, 1) to [lo, hi), without overflowing when hi-lo is not representable.
+func lerp(lo float64, hi float64, u float64) float64 {
+	if d := hi - lo; !math.IsInf(d, 0) {
+		return lo + d*u
+	}
+	return lo*(1-u) + hi*u
+}
+
+// clampFloat moves x into [lo, hi), compensating for rounding errors.
+func clampFloat[T constraints.Float](x T, lo T, hi T) T {
+	if x >= hi {
+		if unsafe.Sizeof(hi) == 4 {
+			return T(math.Nextafter32(float32(hi), float32(lo)))
+		}
+		return T(math.Nextafter(float64(hi), float64(lo)))
+	}
+	if x < lo {
+		return lo
+	}
+	return x
+}
+
+// useFloyd reports whether sampling k of n elements is cheaper with floydSample than with
+// a partial Fisher-Yates shuffle of all n elements.
+func useFloyd(n int, k int) bool {
+	return k < n/32
+}
+
+// floydSample implements Robert Floyd's algorithm for sampling k distinct integers from [0, n)
+// in O(k) time and space, followed by a shuffle to make the order of the result random.
+func floydSample(r *Rand, n int, k int) []int {
+	p := make([]int, 0, k)
+	seen := make(map[int]struct{}, k)
+	for j := n - k; j < n; j++ {
+		t := int(r.Uint64n(uint64(j) + 1))
+		if _, ok := seen[t]; ok {
+			t = j
+		}
+		seen[t] = struct{}{}
+		p = append(p, t)
+	}
+	Shuffle(r, p)
+	return p
+}
@@ -10,7 +10,11 @@ package rand_test
 
 import (
 	"bytes"
+	"fmt"
+	"math"
+	"sort"
 	"testing"
+	"time"
 
 	"pgregory.net/rapid"
 
@@ -44,3 +48,364 @@ func TestShuffle(t *testing.T) {
 		}
 	})
 }
+
+func BenchmarkBetween(b *testing.B) {
+	var s int
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = rand.Between(r, -small, small)
+	}
+	sinkInt = s
+}
+
+func BenchmarkUniform(b *testing.B) {
+	var s float64
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = rand.Uniform(r, -small, float64(small))
+	}
+	sinkFloat64 = s
+}
+
+func TestBetween(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		lo := rapid.Int64().Draw(t, "lo").(int64)
+		hi := rapid.Int64Min(lo).Draw(t, "hi").(int64)
+		v := rand.Between(r, lo, hi)
+		if v < lo || v > hi {
+			t.Fatalf("got %v outside of [%v, %v]", v, lo, hi)
+		}
+		ulo := rapid.Uint8().Draw(t, "ulo").(uint8)
+		uhi := rapid.Uint8Min(ulo).Draw(t, "uhi").(uint8)
+		u := rand.Between(r, ulo, uhi)
+		if u < ulo || u > uhi {
+			t.Fatalf("got %v outside of [%v, %v]", u, ulo, uhi)
+		}
+	})
+}
+
+func TestBetween_FullRange(t *testing.T) {
+	r := rand.New(1)
+	var neg, pos int
+	for i := 0; i < small; i++ {
+		if rand.Between(r, int64(math.MinInt64), math.MaxInt64) < 0 {
+			neg++
+		} else {
+			pos++
+		}
+	}
+	if neg == 0 || pos == 0 {
+		t.Fatalf("got %v negative and %v non-negative values", neg, pos)
+	}
+}
+
+func TestBetween_Uniformity(t *testing.T) {
+	const n = 1 << 18
+	r := rand.New(1)
+	counts := make([]int, 256)
+	expected := make([]float64, 256)
+	for i := 0; i < n; i++ {
+		counts[int(rand.Between(r, int8(math.MinInt8), math.MaxInt8))+128]++
+	}
+	for i := range expected {
+		expected[i] = n / 256
+	}
+	checkChiSquared(t, counts, expected)
+
+	counts = make([]int, 7)
+	expected = make([]float64, 7)
+	for i := 0; i < n; i++ {
+		counts[rand.Between(r, -3, 3)+3]++
+	}
+	for i := range expected {
+		expected[i] = n / 7.0
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestDuration(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		lo := time.Duration(rapid.Int64().Draw(t, "lo").(int64))
+		hi := time.Duration(rapid.Int64Min(int64(lo)).Draw(t, "hi").(int64))
+		v := rand.Duration(r, lo, hi)
+		if v < lo || v > hi {
+			t.Fatalf("got %v outside of [%v, %v]", v, lo, hi)
+		}
+	})
+}
+
+func TestLogUniform(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		lo := rapid.Float64Range(math.SmallestNonzeroFloat64, math.MaxFloat64).Draw(t, "lo").(float64)
+		hi := rapid.Float64Range(lo, math.MaxFloat64).Filter(func(hi float64) bool { return hi > lo }).Draw(t, "hi").(float64)
+		v := rand.LogUniform(r, lo, hi)
+		if v < lo || v >= hi {
+			t.Fatalf("got %v outside of [%v, %v)", v, lo, hi)
+		}
+	})
+}
+
+func TestUniform(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		lo := rapid.Float64Range(-math.MaxFloat64, math.MaxFloat64).Draw(t, "lo").(float64)
+		hi := rapid.Float64Range(lo, math.MaxFloat64).Filter(func(hi float64) bool { return hi > lo }).Draw(t, "hi").(float64)
+		v := rand.Uniform(r, lo, hi)
+		if v < lo || v >= hi {
+			t.Fatalf("got %v outside of [%v, %v)", v, lo, hi)
+		}
+		lo32 := rapid.Float32Range(-math.MaxFloat32, math.Nextafter32(math.MaxFloat32, 0)).Draw(t, "lo32").(float32)
+		hi32 := math.Nextafter32(lo32, math.MaxFloat32)
+		v32 := rand.Uniform(r, lo32, hi32)
+		if v32 != lo32 {
+			t.Fatalf("got %v instead of %v for [%v, %v)", v32, lo32, lo32, hi32)
+		}
+	})
+}
+
+func BenchmarkChoice(b *testing.B) {
+	var s int
+	r := rand.New(1)
+	a := make([]int, small)
+	for i := 0; i < b.N; i++ {
+		s = rand.Choice(r, a)
+	}
+	sinkInt = s
+}
+
+func BenchmarkSampleK(b *testing.B) {
+	r := rand.New(1)
+	a := make([]int, small)
+	for i := 0; i < b.N; i++ {
+		_ = rand.SampleK(r, a, 10)
+	}
+}
+
+func BenchmarkSampleK_Big(b *testing.B) {
+	r := rand.New(1)
+	a := make([]int, small)
+	for i := 0; i < b.N; i++ {
+		_ = rand.SampleK(r, a, small/2)
+	}
+}
+
+func BenchmarkPartialShuffle(b *testing.B) {
+	r := rand.New(1)
+	a := make([]int, small)
+	for i := 0; i < b.N; i++ {
+		rand.PartialShuffle(r, a, tiny)
+	}
+}
+
+func TestChoice(t *testing.T) {
+	const n = 1 << 16
+	r := rand.New(1)
+	s := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	counts := make([]int, len(s))
+	expected := make([]float64, len(s))
+	for i := 0; i < n; i++ {
+		counts[rand.Choice(r, s)]++
+	}
+	for i := range expected {
+		expected[i] = n / float64(len(s))
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestSampleK(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		n := rapid.IntRange(0, small).Draw(t, "n").(int)
+		k := rapid.IntRange(0, n).Draw(t, "k").(int)
+		a := r.Perm(n)
+		sample := rand.SampleK(r, a, k)
+		if len(sample) != k {
+			t.Fatalf("got %v elements instead of %v", len(sample), k)
+		}
+		seen := map[int]bool{}
+		for _, v := range sample {
+			if v < 0 || v >= n || seen[v] {
+				t.Fatalf("got invalid or duplicate element %v in %v", v, sample)
+			}
+			seen[v] = true
+		}
+	})
+}
+
+func TestSampleIndices(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		n := rapid.IntRange(0, small).Draw(t, "n").(int)
+		k := rapid.IntRange(0, n).Draw(t, "k").(int)
+		sample := rand.SampleIndices(r, n, k)
+		if len(sample) != k {
+			t.Fatalf("got %v indices instead of %v", len(sample), k)
+		}
+		seen := map[int]bool{}
+		for _, v := range sample {
+			if v < 0 || v >= n || seen[v] {
+				t.Fatalf("got invalid or duplicate index %v in %v", v, sample)
+			}
+			seen[v] = true
+		}
+	})
+}
+
+func TestSampleIndices_Uniformity(t *testing.T) {
+	for _, n := range []int{6, 100} { // partial shuffle and Floyd's algorithm
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			const samples = 1 << 20
+			r := rand.New(1)
+			counts := make([]int, n*n)
+			expected := make([]float64, n*n)
+			for i := 0; i < samples; i++ {
+				p := rand.SampleIndices(r, n, 2)
+				counts[p[0]*n+p[1]]++
+			}
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					if i != j {
+						expected[i*n+j] = samples / float64(n*(n-1))
+					}
+				}
+			}
+			checkChiSquared(t, counts, expected)
+		})
+	}
+}
+
+func TestPartialShuffle(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		n := rapid.IntRange(0, small).Draw(t, "n").(int)
+		k := rapid.IntRange(0, n).Draw(t, "k").(int)
+		a := make([]int, n)
+		for i := range a {
+			a[i] = i
+		}
+		rand.PartialShuffle(r, a, k)
+		sort.Ints(a)
+		for i, v := range a {
+			if v != i {
+				t.Fatalf("elements lost or duplicated: %v", a)
+			}
+		}
+	})
+}
+
+func TestPartialShuffle_Uniformity(t *testing.T) {
+	const (
+		n       = 5
+		samples = 1 << 18
+	)
+	r := rand.New(1)
+	counts := make([]int, n*n*n)
+	expected := make([]float64, n*n*n)
+	a := make([]int, n)
+	for i := 0; i < samples; i++ {
+		for j := range a {
+			a[j] = j
+		}
+		rand.PartialShuffle(r, a, 3)
+		counts[(a[0]*n+a[1])*n+a[2]]++
+	}
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			for z := 0; z < n; z++ {
+				if x != y && y != z && x != z {
+					expected[(x*n+y)*n+z] = samples / (n * (n - 1) * (n - 2))
+				}
+			}
+		}
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func BenchmarkParallelShuffle(b *testing.B) {
+	s := make([]int, 1<<20)
+	r := rand.New(1)
+	b.SetBytes(int64(len(s)) * 8)
+	for i := 0; i < b.N; i++ {
+		rand.ParallelShuffle(r, s, 4)
+	}
+}
+
+func TestParallelShuffle(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		seed := rapid.Uint64().Draw(t, "seed").(uint64)
+		n := rapid.IntRange(0, 10*small).Draw(t, "n").(int)
+		workers := rapid.IntRange(1, 16).Draw(t, "workers").(int)
+		a := make([]int, n)
+		for i := range a {
+			a[i] = i
+		}
+		b := append([]int(nil), a...)
+		rand.ParallelShuffle(rand.New(seed), a, workers)
+		rand.ParallelShuffle(rand.New(seed), b, workers)
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			t.Fatalf("results differ for the same seed: %v vs %v", a, b)
+		}
+		sort.Ints(a)
+		for i, v := range a {
+			if v != i {
+				t.Fatalf("elements lost or duplicated: %v", a)
+			}
+		}
+	})
+}
+
+func TestParallelShuffle_ManyWorkers(t *testing.T) {
+	// the number of workers is capped, instead of allocating offsets for workers² buckets
+	const n = 1 << 20
+	a := make([]int, n)
+	for i := range a {
+		a[i] = i
+	}
+	b := append([]int(nil), a...)
+	rand.ParallelShuffle(rand.New(1), a, n)
+	rand.ParallelShuffle(rand.New(1), b, 256)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("got %v instead of %v at index %v", a[i], b[i], i)
+		}
+	}
+	sort.Ints(a)
+	for i, v := range a {
+		if v != i {
+			t.Fatalf("got %v instead of %v at index %v after sorting", v, i, i)
+		}
+	}
+}
+
+func TestParallelShuffle_Uniformity(t *testing.T) {
+	const samples = 1 << 14
+	r := rand.New(1)
+	for _, workers := range []int{2, 3, 4} {
+		counts := map[string]int{}
+		for i := 0; i < samples; i++ {
+			s := []byte("abcd")
+			rand.ParallelShuffle(r, s, workers)
+			counts[string(s)]++
+		}
+		if len(counts) != 24 {
+			t.Fatalf("got %v distinct permutations instead of 24 with %v workers", len(counts), workers)
+		}
+		c := make([]int, 0, len(counts))
+		expected := make([]float64, 0, len(counts))
+		for _, v := range counts {
+			c = append(c, v)
+			expected = append(expected, samples/24.0)
+		}
+		checkChiSquared(t, c, expected)
+	}
+}
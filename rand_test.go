@@ -11,6 +11,7 @@ package rand_test
 import (
 	"bytes"
 	"math"
+	"math/big"
 	"math/bits"
 	"testing"
 
@@ -119,6 +120,33 @@ func BenchmarkRand_GetInt(b *testing.B) {
 	sinkInt = s
 }
 
+func BenchmarkRand_Bernoulli(b *testing.B) {
+	var s bool
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Bernoulli(0.3)
+	}
+	sinkBool = s
+}
+
+func BenchmarkRand_Bits(b *testing.B) {
+	var s uint64
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Bits(5)
+	}
+	sinkUint64 = s
+}
+
+func BenchmarkRand_Bool(b *testing.B) {
+	var s bool
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Bool()
+	}
+	sinkBool = s
+}
+
 func BenchmarkRand_ExpFloat64(b *testing.B) {
 	var s float64
 	r := rand.New(1)
@@ -146,6 +174,78 @@ func BenchmarkRand_Float64(b *testing.B) {
 	sinkFloat64 = s
 }
 
+func BenchmarkRand_Float32Closed(b *testing.B) {
+	var s float32
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Float32Closed()
+	}
+	sinkFloat32 = s
+}
+
+func BenchmarkRand_Float32Full(b *testing.B) {
+	var s float32
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Float32Full()
+	}
+	sinkFloat32 = s
+}
+
+func BenchmarkRand_Float32Open(b *testing.B) {
+	var s float32
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Float32Open()
+	}
+	sinkFloat32 = s
+}
+
+func BenchmarkRand_Float32OpenClosed(b *testing.B) {
+	var s float32
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Float32OpenClosed()
+	}
+	sinkFloat32 = s
+}
+
+func BenchmarkRand_Float64Closed(b *testing.B) {
+	var s float64
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Float64Closed()
+	}
+	sinkFloat64 = s
+}
+
+func BenchmarkRand_Float64Full(b *testing.B) {
+	var s float64
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Float64Full()
+	}
+	sinkFloat64 = s
+}
+
+func BenchmarkRand_Float64Open(b *testing.B) {
+	var s float64
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Float64Open()
+	}
+	sinkFloat64 = s
+}
+
+func BenchmarkRand_Float64OpenClosed(b *testing.B) {
+	var s float64
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s = r.Float64OpenClosed()
+	}
+	sinkFloat64 = s
+}
+
 func BenchmarkRand_Int(b *testing.B) {
 	var s int
 	r := rand.New(1)
@@ -391,6 +491,128 @@ func TestRand_Float64(t *testing.T) {
 	})
 }
 
+func TestRand_Float32Closed(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		f := r.Float32Closed()
+		if f < 0 || f > 1 {
+			t.Fatalf("got %v outside of [0, 1]", f)
+		}
+	})
+}
+
+func TestRand_Float32Full(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		f := r.Float32Full()
+		if f < 0 || f >= 1 {
+			t.Fatalf("got %v outside of [0, 1)", f)
+		}
+	})
+}
+
+func TestRand_Float32Open(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		f := r.Float32Open()
+		if f <= 0 || f >= 1 {
+			t.Fatalf("got %v outside of (0, 1)", f)
+		}
+	})
+}
+
+func TestRand_Float32OpenClosed(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		f := r.Float32OpenClosed()
+		if f <= 0 || f > 1 {
+			t.Fatalf("got %v outside of (0, 1]", f)
+		}
+	})
+}
+
+func TestRand_Float64Closed(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		f := r.Float64Closed()
+		if f < 0 || f > 1 {
+			t.Fatalf("got %v outside of [0, 1]", f)
+		}
+	})
+}
+
+func TestRand_Float64Full(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		f := r.Float64Full()
+		if f < 0 || f >= 1 {
+			t.Fatalf("got %v outside of [0, 1)", f)
+		}
+	})
+}
+
+func TestRand_Float64Open(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		f := r.Float64Open()
+		if f <= 0 || f >= 1 {
+			t.Fatalf("got %v outside of (0, 1)", f)
+		}
+	})
+}
+
+func TestRand_Float64OpenClosed(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		f := r.Float64OpenClosed()
+		if f <= 0 || f > 1 {
+			t.Fatalf("got %v outside of (0, 1]", f)
+		}
+	})
+}
+
+func TestRand_Float64Full_Precision(t *testing.T) {
+	r := rand.New(1)
+	var small, fine int
+	for i := 0; i < 1<<20; i++ {
+		f := r.Float64Full()
+		if f < 0x1.0p-11 {
+			small++
+			if f*(1<<53) != math.Floor(f*(1<<53)) {
+				fine++
+			}
+		}
+	}
+	if small == 0 || fine == 0 {
+		t.Fatalf("got %v values below 2^-11, %v of them not multiples of 2^-53", small, fine)
+	}
+}
+
+func TestRand_Float64Full_Exponent(t *testing.T) {
+	const n = 1 << 20
+	r := rand.New(1)
+	counts := make([]int, 16)
+	for i := 0; i < n; i++ {
+		_, e := math.Frexp(r.Float64Full())
+		if -e < len(counts) {
+			counts[-e]++
+		}
+	}
+	expected := make([]float64, len(counts))
+	for e := range expected {
+		expected[e] = n * math.Ldexp(1, -e-1) // f is in [2^-e-1, 2^-e) with probability 2^-e-1
+	}
+	checkChiSquared(t, counts, expected)
+}
+
 func TestRand_Int31n(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		s := rapid.Uint64().Draw(t, "s").(uint64)
@@ -474,6 +696,110 @@ func TestRand_MarshalBinary_Roundtrip(t *testing.T) {
 	})
 }
 
+func TestRand_MarshalBinary_BitCache(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		k := rapid.IntRange(0, 64).Draw(t, "k").(int)
+		r1 := rand.New(s)
+		r1.Bits(k)
+		data, err := r1.MarshalBinary()
+		if err != nil {
+			t.Fatalf("got unexpected marshal error: %v", err)
+		}
+		var r2 rand.Rand
+		err = r2.UnmarshalBinary(data)
+		if err != nil {
+			t.Fatalf("got unexpected unmarshal error: %v", err)
+		}
+		for i := 0; i < 100; i++ {
+			if b1, b2 := r1.Bool(), r2.Bool(); b1 != b2 {
+				t.Fatalf("got different bits after marshal/unmarshal")
+			}
+		}
+	})
+}
+
+func TestRand_UnmarshalBinary_Invalid(t *testing.T) {
+	r1 := rand.New(1)
+	r1.Bits(5)
+	data, _ := r1.MarshalBinary()
+	n := len(data)
+	for _, c := range []struct {
+		name   string
+		mutate func(d []byte) []byte
+	}{
+		{"truncated state", func(d []byte) []byte { return d[:n-10] }},
+		{"truncated bit cache", func(d []byte) []byte { return d[:n-1] }},
+		{"too many cached bits", func(d []byte) []byte { d[n-1] = 65; return d }},
+		{"bits outside of the cache", func(d []byte) []byte { d[n-9] |= 1; return d }},
+		{"bits in an empty cache", func(d []byte) []byte { d[n-1] = 0; return d }},
+	} {
+		d := c.mutate(append([]byte(nil), data...))
+		r2 := rand.New(2)
+		want, _ := r2.MarshalBinary()
+		if err := r2.UnmarshalBinary(d); err == nil {
+			t.Errorf("%v: got no error", c.name)
+		}
+		if got, _ := r2.MarshalBinary(); !bytes.Equal(got, want) {
+			t.Errorf("%v: the generator changed after the error", c.name)
+		}
+	}
+}
+
+func TestRand_Bits(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r1 := rand.New(s)
+		r2 := rand.New(s)
+		ks := rapid.SliceOf(rapid.IntRange(0, 64)).Draw(t, "ks").([]int)
+		for _, k := range ks {
+			v := r1.Bits(k)
+			if k < 64 && v>>k != 0 {
+				t.Fatalf("Bits(%v) = %#x", k, v)
+			}
+			for i := k - 1; i >= 0; i-- {
+				if b := r2.Bool(); b != (v>>i&1 != 0) {
+					t.Fatalf("Bits(%v) = %#x differs from Bool at bit %v", k, v, i)
+				}
+			}
+		}
+	})
+}
+
+func TestRand_Bool_Uniformity(t *testing.T) {
+	const n = 1 << 16
+	r := rand.New(1)
+	counts := make([]int, 4)
+	for i := 0; i < n; i++ {
+		b1, b2 := r.Bool(), r.Bool()
+		j := 0
+		if b1 {
+			j |= 1
+		}
+		if b2 {
+			j |= 2
+		}
+		counts[j]++
+	}
+	checkChiSquared(t, counts, []float64{n / 4, n / 4, n / 4, n / 4})
+}
+
+func TestRand_Bernoulli(t *testing.T) {
+	const n = 1 << 16
+	r := rand.New(1)
+	for _, p := range []float64{0, 1e-3, 0.1, 1.0 / 3, 0.5, 0.9, 1 - 0x1p-53, 1} {
+		counts := make([]int, 2)
+		for i := 0; i < n; i++ {
+			if r.Bernoulli(p) {
+				counts[1]++
+			} else {
+				counts[0]++
+			}
+		}
+		checkChiSquared(t, counts, []float64{n * (1 - p), n * p})
+	}
+}
+
 func TestRand_Uint32nOpt(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		n := rapid.Uint32().Draw(t, "n").(uint32)
@@ -491,3 +817,237 @@ func TestRand_Uint32nOpt(t *testing.T) {
 		}
 	})
 }
+
+func BenchmarkRand_FillBernoulliBits(b *testing.B) {
+	dst := make([]uint64, small)
+	r := rand.New(1)
+	b.SetBytes(int64(len(dst)) * 8)
+	for i := 0; i < b.N; i++ {
+		r.FillBernoulliBits(dst, 0.25)
+	}
+}
+
+func BenchmarkRand_SparseBits(b *testing.B) {
+	var dst []int
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		dst = r.SparseBits(dst[:0], 64*small, 0.01)
+	}
+}
+
+func TestRand_FillBernoulliBits(t *testing.T) {
+	const words = 1 << 12
+	r := rand.New(1)
+	dst := make([]uint64, words)
+	for _, p := range []float64{0, 1e-4, 0.01, 1.0 / 32, 0.1, 0.25, 1.0 / 3, 0.5, 0.9, 0.99, 1} {
+		r.FillBernoulliBits(dst, p)
+		counts := make([]int, 2)
+		positions := make([]int, 64)
+		for _, w := range dst {
+			n := bits.OnesCount64(w)
+			counts[1] += n
+			counts[0] += 64 - n
+			for j := 0; j < 64; j++ {
+				positions[j] += int(w >> j & 1)
+			}
+		}
+		checkChiSquared(t, counts, []float64{words * 64 * (1 - p), words * 64 * p})
+		expected := make([]float64, 64)
+		for j := range expected {
+			expected[j] = words * p
+		}
+		if p > 0 {
+			checkChiSquared(t, positions, expected)
+		}
+	}
+}
+
+func TestRand_FillBernoulliBits_Remainder(t *testing.T) {
+	// with 2 bits of p = 0.3, a quarter of the bits is set by combining words,
+	// and the rest of the probability comes from the remainder correction
+	const words = 1 << 12
+	const p = 0.3
+	r := rand.New(1)
+	dst := make([]uint64, words)
+	rand.FillBernoulliBitsForTest(r, dst, p, 2)
+	counts := make([]int, 2)
+	for _, w := range dst {
+		n := bits.OnesCount64(w)
+		counts[1] += n
+		counts[0] += 64 - n
+	}
+	checkChiSquared(t, counts, []float64{words * 64 * (1 - p), words * 64 * p})
+}
+
+func TestRand_SparseBits(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		n := rapid.IntRange(0, 100*small).Draw(t, "n").(int)
+		p := rapid.Float64Range(0, 1).Draw(t, "p").(float64)
+		prefix := rapid.SliceOf(rapid.Int()).Draw(t, "prefix").([]int)
+		r := rand.New(s)
+		ix := r.SparseBits(append([]int(nil), prefix...), n, p)
+		for i, v := range ix[len(prefix):] {
+			if v < 0 || v >= n || (i > 0 && v <= ix[len(prefix)+i-1]) {
+				t.Fatalf("got invalid index %v in %v", v, ix[len(prefix):])
+			}
+		}
+		if p == 1 && len(ix)-len(prefix) != n {
+			t.Fatalf("got %v indices instead of %v for p = 1", len(ix)-len(prefix), n)
+		}
+	})
+}
+
+func TestRand_SparseBits_Distribution(t *testing.T) {
+	const (
+		n       = 100
+		samples = 1 << 14
+		p       = 0.05
+	)
+	r := rand.New(1)
+	counts := make([]int, n)
+	pairs := make([]int, 2)
+	for i := 0; i < samples; i++ {
+		set := map[int]bool{}
+		for _, v := range r.SparseBits(nil, n, p) {
+			counts[v]++
+			set[v] = true
+		}
+		if set[0] && set[1] {
+			pairs[1]++
+		} else {
+			pairs[0]++
+		}
+	}
+	expected := make([]float64, n)
+	for i := range expected {
+		expected[i] = samples * p
+	}
+	checkChiSquared(t, counts, expected)
+	checkChiSquared(t, pairs, []float64{samples * (1 - p*p), samples * p * p})
+}
+
+func BenchmarkRand_BigInt(b *testing.B) {
+	max := new(big.Int).Lsh(big.NewInt(small), 200)
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		r.BigInt(max)
+	}
+}
+
+func BenchmarkRand_Uint128n(b *testing.B) {
+	var s uint64
+	r := rand.New(1)
+	for i := 0; i < b.N; i++ {
+		s, _ = r.Uint128n(small, math.MaxUint64-small)
+	}
+	sinkUint64 = s
+}
+
+func TestRand_BigInt(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		words := rapid.SliceOfN(rapid.Uint64(), 1, 5).Draw(t, "max").([]uint64)
+		max := new(big.Int)
+		for _, w := range words {
+			max.Lsh(max, 64).Or(max, new(big.Int).SetUint64(w))
+		}
+		if max.Sign() == 0 {
+			max.SetInt64(1)
+		}
+		v := rand.New(s).BigInt(max)
+		if v.Sign() < 0 || v.Cmp(max) >= 0 {
+			t.Fatalf("got %v outside of [0, %v)", v, max)
+		}
+	})
+}
+
+func TestRand_BigIntBits(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		n := rapid.IntRange(0, 300).Draw(t, "n").(int)
+		if v := rand.New(s).BigIntBits(n); v.Sign() < 0 || v.BitLen() > n {
+			t.Fatalf("got %v with more than %v bits", v, n)
+		}
+	})
+}
+
+func TestRand_BigInt_Uniformity(t *testing.T) {
+	const samples = 1 << 14
+	r := rand.New(1)
+	for _, bucket := range []uint{0, 61, 64, 130} {
+		max := new(big.Int).Lsh(big.NewInt(7), bucket)
+		counts := make([]int, 7)
+		for i := 0; i < samples; i++ {
+			counts[new(big.Int).Rsh(r.BigInt(max), bucket).Int64()]++
+		}
+		checkChiSquared(t, counts, []float64{samples / 7, samples / 7, samples / 7, samples / 7, samples / 7, samples / 7, samples / 7})
+	}
+}
+
+func TestRand_Uint128n(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		hi := rapid.Uint64().Draw(t, "hi").(uint64)
+		lo := rapid.Uint64().Draw(t, "lo").(uint64)
+		vh, vl := rand.New(s).Uint128n(hi, lo)
+		if hi == 0 && lo == 0 {
+			if vh != 0 || vl != 0 {
+				t.Fatalf("got (%v, %v) for n = 0", vh, vl)
+			}
+			return
+		}
+		if vh > hi || (vh == hi && vl >= lo) {
+			t.Fatalf("got (%v, %v) outside of [0, (%v, %v))", vh, vl, hi, lo)
+		}
+		// BigInt uses the same algorithm
+		max := new(big.Int).Lsh(new(big.Int).SetUint64(hi), 64)
+		max.Or(max, new(big.Int).SetUint64(lo))
+		v := new(big.Int).Lsh(new(big.Int).SetUint64(vh), 64)
+		v.Or(v, new(big.Int).SetUint64(vl))
+		if b := rand.New(s).BigInt(max); b.Cmp(v) != 0 {
+			t.Fatalf("BigInt returned %v instead of %v", b, v)
+		}
+	})
+}
+
+func TestRand_Uint128n_EdgeCases(t *testing.T) {
+	for _, c := range []struct {
+		hi, lo, uh, ul, v uint64
+	}{
+		{1, 0xbc95ebb79ec5911f, math.MaxUint64, math.MaxUint64, 0x72cb12257330076b},
+		{1, 0, 0, 0, 0},
+		{1, 0, math.MaxUint64, math.MaxUint64, math.MaxUint64},
+		{1, 1, math.MaxUint64, math.MaxUint64, math.MaxUint64},
+		{math.MaxUint64, math.MaxUint64, math.MaxUint64, math.MaxUint64, math.MaxUint64},
+		{math.MaxUint64, math.MaxUint64, math.MaxUint64, math.MaxUint64, 0},
+		{math.MaxUint64, math.MaxUint64, 0, 0, math.MaxUint64},
+		{math.MaxUint64, 1, math.MaxUint64, 0, math.MaxUint64},
+		{1 << 63, 0, 1 << 63, 0, 1 << 63},
+	} {
+		// the result is the integer part of n * uh:ul:v / 2^192
+		n := new(big.Int).Lsh(new(big.Int).SetUint64(c.hi), 64)
+		n.Or(n, new(big.Int).SetUint64(c.lo))
+		u := new(big.Int).Lsh(new(big.Int).SetUint64(c.uh), 128)
+		u.Or(u, new(big.Int).Lsh(new(big.Int).SetUint64(c.ul), 64))
+		u.Or(u, new(big.Int).SetUint64(c.v))
+		want := new(big.Int).Rsh(new(big.Int).Mul(n, u), 192)
+		vh, vl := rand.Uint128nForTest(c.hi, c.lo, c.uh, c.ul, c.v)
+		got := new(big.Int).Lsh(new(big.Int).SetUint64(vh), 64)
+		got.Or(got, new(big.Int).SetUint64(vl))
+		if got.Cmp(want) != 0 || got.Cmp(n) >= 0 {
+			t.Errorf("got %#x instead of %#x for n = %#x, u = %#x", got, want, n, u)
+		}
+	}
+}
+
+func TestRand_Uint128n_Uniformity(t *testing.T) {
+	const samples = 1 << 14
+	r := rand.New(1)
+	counts := make([]int, 5)
+	for i := 0; i < samples; i++ {
+		hi, _ := r.Uint128n(5, 0)
+		counts[hi]++
+	}
+	checkChiSquared(t, counts, []float64{samples / 5, samples / 5, samples / 5, samples / 5, samples / 5})
+}
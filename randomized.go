@@ -0,0 +1,172 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build go1.18
+
+package rand
+
+// RandomizedSet is a set that supports choosing a uniformly distributed pseudo-random element.
+// Add, Remove, Contains, Random and PopRandom all take O(1) time.
+//
+// RandomizedSet keeps elements in a slice and their positions in a map; removal moves the last
+// element of the slice into the freed position. The zero value is an empty set ready to use.
+//
+// RandomizedSet is not safe for concurrent use.
+type RandomizedSet[K comparable] struct {
+	m RandomizedMap[K, struct{}]
+}
+
+// Add adds k to the set, and reports whether it was not in the set already.
+func (s *RandomizedSet[K]) Add(k K) bool {
+	return s.m.Set(k, struct{}{})
+}
+
+// Contains reports whether k is in the set.
+func (s *RandomizedSet[K]) Contains(k K) bool {
+	_, ok := s.m.Get(k)
+	return ok
+}
+
+// Elements returns a new slice of all elements of the set, in unspecified order.
+func (s *RandomizedSet[K]) Elements() []K {
+	return s.m.Keys()
+}
+
+// Len returns the number of elements in the set.
+func (s *RandomizedSet[K]) Len() int {
+	return s.m.Len()
+}
+
+// PopRandom removes and returns a uniformly distributed pseudo-random element of the set.
+// It returns false if the set is empty.
+func (s *RandomizedSet[K]) PopRandom(r *Rand) (K, bool) {
+	k, _, ok := s.m.PopRandom(r)
+	return k, ok
+}
+
+// Random returns a uniformly distributed pseudo-random element of the set.
+// It returns false if the set is empty.
+func (s *RandomizedSet[K]) Random(r *Rand) (K, bool) {
+	k, _, ok := s.m.Random(r)
+	return k, ok
+}
+
+// RandomK returns a new slice of k distinct pseudo-randomly chosen elements of the set,
+// in pseudo-random order. It panics if k < 0 or k > [RandomizedSet.Len].
+func (s *RandomizedSet[K]) RandomK(r *Rand, k int) []K {
+	return s.m.RandomK(r, k)
+}
+
+// Remove removes k from the set, and reports whether it was in the set.
+func (s *RandomizedSet[K]) Remove(k K) bool {
+	return s.m.Delete(k)
+}
+
+// RandomizedMap is a map that supports choosing a uniformly distributed pseudo-random key.
+// Set, Get, Delete, Random and PopRandom all take O(1) time.
+//
+// RandomizedMap keeps entries in slices and their positions in a map; deletion moves the last
+// entry into the freed position. The zero value is an empty map ready to use.
+//
+// RandomizedMap is not safe for concurrent use.
+type RandomizedMap[K comparable, V any] struct {
+	keys  []K
+	vals  []V
+	index map[K]int
+}
+
+// Delete removes the entry for k, and reports whether it was in the map.
+func (m *RandomizedMap[K, V]) Delete(k K) bool {
+	i, ok := m.index[k]
+	if !ok {
+		return false
+	}
+	m.removeAt(i)
+	return true
+}
+
+// Get returns the value for k, and reports whether it was in the map.
+func (m *RandomizedMap[K, V]) Get(k K) (V, bool) {
+	i, ok := m.index[k]
+	if !ok {
+		var v V
+		return v, false
+	}
+	return m.vals[i], true
+}
+
+// Keys returns a new slice of all keys of the map, in unspecified order.
+func (m *RandomizedMap[K, V]) Keys() []K {
+	return append([]K(nil), m.keys...)
+}
+
+// Len returns the number of entries in the map.
+func (m *RandomizedMap[K, V]) Len() int {
+	return len(m.keys)
+}
+
+// PopRandom removes and returns a uniformly distributed pseudo-random entry of the map.
+// It returns false if the map is empty.
+func (m *RandomizedMap[K, V]) PopRandom(r *Rand) (K, V, bool) {
+	if len(m.keys) == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	i := int(r.Uint64n(uint64(len(m.keys))))
+	k, v := m.keys[i], m.vals[i]
+	m.removeAt(i)
+	return k, v, true
+}
+
+// Random returns a uniformly distributed pseudo-random entry of the map.
+// It returns false if the map is empty.
+func (m *RandomizedMap[K, V]) Random(r *Rand) (K, V, bool) {
+	if len(m.keys) == 0 {
+		var k K
+		var v V
+		return k, v, false
+	}
+	i := int(r.Uint64n(uint64(len(m.keys))))
+	return m.keys[i], m.vals[i], true
+}
+
+// RandomK returns a new slice of k distinct pseudo-randomly chosen keys of the map,
+// in pseudo-random order. It panics if k < 0 or k > [RandomizedMap.Len].
+func (m *RandomizedMap[K, V]) RandomK(r *Rand, k int) []K {
+	if k < 0 || k > len(m.keys) {
+		panic("invalid argument to RandomK")
+	}
+	return SampleK(r, m.keys, k)
+}
+
+// Set sets the value for k, and reports whether k was not in the map already.
+func (m *RandomizedMap[K, V]) Set(k K, v V) bool {
+	if i, ok := m.index[k]; ok {
+		m.vals[i] = v
+		return false
+	}
+	if m.index == nil {
+		m.index = map[K]int{}
+	}
+	m.index[k] = len(m.keys)
+	m.keys = append(m.keys, k)
+	m.vals = append(m.vals, v)
+	return true
+}
+
+func (m *RandomizedMap[K, V]) removeAt(i int) {
+	last := len(m.keys) - 1
+	delete(m.index, m.keys[i])
+	if i != last {
+		m.keys[i], m.vals[i] = m.keys[last], m.vals[last]
+		m.index[m.keys[i]] = i
+	}
+	var k K
+	var v V
+	m.keys[last], m.vals[last] = k, v // allow garbage collection
+	m.keys, m.vals = m.keys[:last], m.vals[:last]
+}
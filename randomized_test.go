@@ -0,0 +1,153 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build go1.18
+
+package rand_test
+
+import (
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/kokizzu/rand"
+)
+
+func BenchmarkRandomizedSet_Random(b *testing.B) {
+	var s rand.RandomizedSet[int]
+	for i := 0; i < small; i++ {
+		s.Add(i)
+	}
+	r := rand.New(1)
+	var v int
+	for i := 0; i < b.N; i++ {
+		v, _ = s.Random(r)
+	}
+	sinkInt = v
+}
+
+func TestRandomizedMap_Model(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		r := rand.New(rapid.Uint64().Draw(t, "s").(uint64))
+		var m rand.RandomizedMap[int, int]
+		model := map[int]int{}
+		key := rapid.IntRange(0, 20)
+		ops := rapid.IntRange(0, 100).Draw(t, "ops").(int)
+		for op := 0; op < ops; op++ {
+			switch rapid.IntRange(0, 4).Draw(t, "op").(int) {
+			case 0:
+				k, v := key.Draw(t, "k").(int), rapid.Int().Draw(t, "v").(int)
+				_, ok := model[k]
+				if m.Set(k, v) == ok {
+					t.Fatalf("Set(%v) reported wrong presence", k)
+				}
+				model[k] = v
+			case 1:
+				k := key.Draw(t, "k").(int)
+				_, ok := model[k]
+				if m.Delete(k) != ok {
+					t.Fatalf("Delete(%v) reported wrong presence", k)
+				}
+				delete(model, k)
+			case 2:
+				k, v, ok := m.PopRandom(r)
+				if ok != (len(model) > 0) {
+					t.Fatalf("PopRandom returned %v with %v entries", ok, len(model))
+				}
+				if ok {
+					if mv, found := model[k]; !found || mv != v {
+						t.Fatalf("PopRandom returned (%v, %v) not in the map", k, v)
+					}
+					delete(model, k)
+				}
+			case 3:
+				k, v, ok := m.Random(r)
+				if ok != (len(model) > 0) {
+					t.Fatalf("Random returned %v with %v entries", ok, len(model))
+				}
+				if mv, found := model[k]; ok && (!found || mv != v) {
+					t.Fatalf("Random returned (%v, %v) not in the map", k, v)
+				}
+			case 4:
+				n := rapid.IntRange(0, len(model)).Draw(t, "n").(int)
+				seen := map[int]bool{}
+				for _, k := range m.RandomK(r, n) {
+					if _, found := model[k]; !found || seen[k] {
+						t.Fatalf("RandomK returned missing or duplicate key %v", k)
+					}
+					seen[k] = true
+				}
+				if len(seen) != n {
+					t.Fatalf("RandomK returned %v keys instead of %v", len(seen), n)
+				}
+			}
+			if m.Len() != len(model) {
+				t.Fatalf("got length %v instead of %v", m.Len(), len(model))
+			}
+		}
+		for k, v := range model {
+			if mv, ok := m.Get(k); !ok || mv != v {
+				t.Fatalf("Get(%v) = (%v, %v) instead of %v", k, mv, ok, v)
+			}
+		}
+		if len(m.Keys()) != len(model) {
+			t.Fatalf("got %v keys instead of %v", len(m.Keys()), len(model))
+		}
+	})
+}
+
+func TestRandomizedSet(t *testing.T) {
+	var s rand.RandomizedSet[string]
+	r := rand.New(1)
+	if _, ok := s.Random(r); ok {
+		t.Fatalf("Random succeeded on empty set")
+	}
+	for _, e := range []string{"a", "b", "c", "a"} {
+		s.Add(e)
+	}
+	if s.Len() != 3 || !s.Contains("b") || s.Contains("d") || len(s.Elements()) != 3 {
+		t.Fatalf("unexpected set state: %v", s.Elements())
+	}
+	if !s.Remove("b") || s.Remove("b") || s.Contains("b") {
+		t.Fatalf("Remove did not remove the element")
+	}
+	seen := map[string]bool{}
+	for _, e := range s.RandomK(r, 2) {
+		seen[e] = true
+	}
+	for s.Len() > 0 {
+		e, _ := s.PopRandom(r)
+		delete(seen, e)
+	}
+	if len(seen) != 0 {
+		t.Fatalf("RandomK returned elements not in the set: %v", seen)
+	}
+}
+
+func TestRandomizedSet_Uniformity(t *testing.T) {
+	const (
+		n       = 10
+		samples = 1 << 16
+	)
+	var s rand.RandomizedSet[int]
+	for i := 0; i < 2*n; i++ {
+		s.Add(i)
+	}
+	for i := 0; i < 2*n; i += 2 {
+		s.Remove(i) // shuffle internal positions
+	}
+	r := rand.New(1)
+	counts := make([]int, 2*n)
+	for i := 0; i < samples; i++ {
+		v, _ := s.Random(r)
+		counts[v]++
+	}
+	expected := make([]float64, 2*n)
+	for i := 1; i < 2*n; i += 2 {
+		expected[i] = samples / n
+	}
+	checkChiSquared(t, counts, expected)
+}
@@ -0,0 +1,225 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build go1.18
+
+package rand
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Reservoir keeps a uniform pseudo-random sample of up to k items from a stream of unknown length.
+//
+// Reservoir implements Algorithm L by Kim-Hung Li: once the reservoir is full, it computes how many
+// of the following items to skip, so the number of random numbers used is O(k log(n/k)) instead of O(n).
+// Every item in the reservoir carries a uniform random key, and the sample consists of items
+// with k smallest keys; this makes it possible to [Reservoir.Merge] reservoirs that saw different streams.
+//
+// Reservoir is not safe for concurrent use.
+type Reservoir[T any] struct {
+	k    int
+	n    uint64
+	skip uint64
+	h    keyedHeap[T]
+}
+
+// NewReservoir returns an empty reservoir for a sample of up to k items. It panics if k <= 0.
+func NewReservoir[T any](k int) *Reservoir[T] {
+	if k <= 0 {
+		panic("invalid argument to NewReservoir")
+	}
+	return &Reservoir[T]{k: k, h: make(keyedHeap[T], 0, k)}
+}
+
+// Add offers the next item of the stream to the reservoir.
+func (rs *Reservoir[T]) Add(r *Rand, x T) {
+	rs.n++
+	if len(rs.h) < rs.k {
+		heap.Push(&rs.h, keyedItem[T]{r.Float64(), x})
+		if len(rs.h) == rs.k {
+			rs.drawSkip(r)
+		}
+		return
+	}
+	if rs.skip > 0 {
+		rs.skip--
+		return
+	}
+	// key of an accepted item is uniform below the current threshold
+	rs.h[0] = keyedItem[T]{rs.h[0].key * r.Float64(), x}
+	heap.Fix(&rs.h, 0)
+	rs.drawSkip(r)
+}
+
+// drawSkip draws the number of items whose key is above the threshold before the next one below it.
+func (rs *Reservoir[T]) drawSkip(r *Rand) {
+	s := math.Floor(math.Log(r.Float64Open()) / math.Log1p(-rs.h[0].key))
+	if s >= 1<<64 {
+		rs.skip = math.MaxUint64
+	} else {
+		rs.skip = uint64(s)
+	}
+}
+
+// Count returns the number of items offered to the reservoir.
+func (rs *Reservoir[T]) Count() uint64 {
+	return rs.n
+}
+
+// Merge adds the sample of other to rs, as if rs has seen the items of both streams.
+// Other is not modified. Merge panics if the reservoirs have different sample sizes.
+func (rs *Reservoir[T]) Merge(r *Rand, other *Reservoir[T]) {
+	if rs.k != other.k {
+		panic("invalid argument to Merge")
+	}
+	rs.n += other.n
+	if rs.h.merge(rs.k, other.h) {
+		rs.drawSkip(r)
+	}
+}
+
+// Sample returns the items in the reservoir, in no particular order.
+func (rs *Reservoir[T]) Sample() []T {
+	return rs.h.items()
+}
+
+// Skip returns the number of next items [Reservoir.Add] is going to discard without using r.
+// Callers that can skip items cheaply can call [Reservoir.SkipN] instead of producing them.
+func (rs *Reservoir[T]) Skip() uint64 {
+	return rs.skip
+}
+
+// SkipN is equivalent to calling [Reservoir.Add] with n items, which are all discarded.
+// It panics if n > [Reservoir.Skip].
+func (rs *Reservoir[T]) SkipN(n uint64) {
+	if n > rs.skip {
+		panic("invalid argument to SkipN")
+	}
+	rs.n += n
+	rs.skip -= n
+}
+
+// WeightedReservoir keeps a weighted pseudo-random sample of up to k items from a stream of unknown length.
+// The sample is distributed like the first k items chosen one after another, with probability proportional
+// to their weight among the items not chosen yet. Items with zero weight are never chosen.
+//
+// WeightedReservoir implements A-Res by Efraimidis and Spirakis, with exponential jumps (A-ExpJ):
+// once the reservoir is full, only O(k log(n/k)) random numbers are used. Every item in the reservoir
+// carries a random key, and the sample consists of items with k smallest keys; this makes it possible
+// to [WeightedReservoir.Merge] reservoirs that saw different streams.
+//
+// WeightedReservoir is not safe for concurrent use.
+type WeightedReservoir[T any] struct {
+	k    int
+	n    uint64
+	jump float64 // total weight to skip before the next item is accepted
+	h    keyedHeap[T]
+}
+
+// NewWeightedReservoir returns an empty reservoir for a sample of up to k items. It panics if k <= 0.
+func NewWeightedReservoir[T any](k int) *WeightedReservoir[T] {
+	if k <= 0 {
+		panic("invalid argument to NewWeightedReservoir")
+	}
+	return &WeightedReservoir[T]{k: k, h: make(keyedHeap[T], 0, k)}
+}
+
+// Add offers the next item of the stream with weight w to the reservoir.
+// It panics if w is negative, infinite or NaN.
+func (rs *WeightedReservoir[T]) Add(r *Rand, x T, w float64) {
+	if !validWeight(w) {
+		panic("invalid argument to Add")
+	}
+	rs.n++
+	if w == 0 {
+		return
+	}
+	if len(rs.h) < rs.k {
+		heap.Push(&rs.h, keyedItem[T]{r.ExpFloat64() / w, x})
+		if len(rs.h) == rs.k {
+			rs.drawJump(r)
+		}
+		return
+	}
+	rs.jump -= w
+	if rs.jump > 0 {
+		return
+	}
+	// key E/w of an accepted item is exponential, truncated to the current threshold
+	t := -math.Expm1(-w * rs.h[0].key)
+	rs.h[0] = keyedItem[T]{-math.Log1p(-r.Float64()*t) / w, x}
+	heap.Fix(&rs.h, 0)
+	rs.drawJump(r)
+}
+
+// drawJump draws the total weight of items with key above the threshold before the next one below it.
+func (rs *WeightedReservoir[T]) drawJump(r *Rand) {
+	rs.jump = r.ExpFloat64() / rs.h[0].key
+}
+
+// Count returns the number of items offered to the reservoir.
+func (rs *WeightedReservoir[T]) Count() uint64 {
+	return rs.n
+}
+
+// Merge adds the sample of other to rs, as if rs has seen the items of both streams.
+// Other is not modified. Merge panics if the reservoirs have different sample sizes.
+func (rs *WeightedReservoir[T]) Merge(r *Rand, other *WeightedReservoir[T]) {
+	if rs.k != other.k {
+		panic("invalid argument to Merge")
+	}
+	rs.n += other.n
+	if rs.h.merge(rs.k, other.h) {
+		rs.drawJump(r)
+	}
+}
+
+// Sample returns the items in the reservoir, in no particular order.
+func (rs *WeightedReservoir[T]) Sample() []T {
+	return rs.h.items()
+}
+
+type keyedItem[T any] struct {
+	key  float64
+	item T
+}
+
+// keyedHeap is a max-heap of items by key.
+type keyedHeap[T any] []keyedItem[T]
+
+func (h keyedHeap[T]) Len() int           { return len(h) }
+func (h keyedHeap[T]) Less(i, j int) bool { return h[i].key > h[j].key }
+func (h keyedHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyedHeap[T]) Push(x any)        { *h = append(*h, x.(keyedItem[T])) }
+func (h *keyedHeap[T]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// merge keeps k items with smallest keys from h and other, and reports whether h is full.
+func (h *keyedHeap[T]) merge(k int, other keyedHeap[T]) bool {
+	for _, e := range other {
+		if len(*h) < k {
+			heap.Push(h, e)
+		} else if e.key < (*h)[0].key {
+			(*h)[0] = e
+			heap.Fix(h, 0)
+		}
+	}
+	return len(*h) == k
+}
+
+func (h keyedHeap[T]) items() []T {
+	s := make([]T, len(h))
+	for i, e := range h {
+		s[i] = e.item
+	}
+	return s
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build go1.18
+
+package rand_test
+
+import (
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/kokizzu/rand"
+)
+
+func BenchmarkReservoir_Add(b *testing.B) {
+	r := rand.New(1)
+	rs := rand.NewReservoir[int](small)
+	for i := 0; i < b.N; i++ {
+		rs.Add(r, i)
+	}
+}
+
+func BenchmarkWeightedReservoir_Add(b *testing.B) {
+	r := rand.New(1)
+	rs := rand.NewWeightedReservoir[int](small)
+	for i := 0; i < b.N; i++ {
+		rs.Add(r, i, 1)
+	}
+}
+
+func TestReservoir(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		k := rapid.IntRange(1, 10).Draw(t, "k").(int)
+		n := rapid.IntRange(0, 1000).Draw(t, "n").(int)
+		r := rand.New(rapid.Uint64().Draw(t, "seed").(uint64))
+		rs := rand.NewReservoir[int](k)
+		for i := 0; i < n; i++ {
+			if s := rs.Skip(); s > 1 && rapid.Bool().Draw(t, "skip").(bool) {
+				if s > uint64(n-i) {
+					s = uint64(n - i)
+				}
+				rs.SkipN(s)
+				i += int(s) - 1
+				continue
+			}
+			rs.Add(r, i)
+		}
+		if rs.Count() != uint64(n) {
+			t.Fatalf("got count %v instead of %v", rs.Count(), n)
+		}
+		s := rs.Sample()
+		want := k
+		if n < k {
+			want = n
+		}
+		if len(s) != want {
+			t.Fatalf("got %v items instead of %v", len(s), want)
+		}
+		seen := map[int]bool{}
+		for _, x := range s {
+			if x < 0 || x >= n || seen[x] {
+				t.Fatalf("got invalid or repeated item %v in %v", x, s)
+			}
+			seen[x] = true
+		}
+	})
+}
+
+func TestReservoir_Distribution(t *testing.T) {
+	const (
+		n      = 20
+		k      = 3
+		trials = 1 << 16
+	)
+	r := rand.New(1)
+	counts := make([]int, n)
+	for i := 0; i < trials; i++ {
+		rs := rand.NewReservoir[int](k)
+		for j := 0; j < n; j++ {
+			rs.Add(r, j)
+		}
+		for _, x := range rs.Sample() {
+			counts[x]++
+		}
+	}
+	expected := make([]float64, n)
+	for i := range expected {
+		expected[i] = trials * k / n
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestReservoir_Merge(t *testing.T) {
+	const (
+		n      = 20
+		k      = 3
+		trials = 1 << 16
+	)
+	r := rand.New(1)
+	counts := make([]int, n)
+	for i := 0; i < trials; i++ {
+		a := rand.NewReservoir[int](k)
+		b := rand.NewReservoir[int](k)
+		for j := 0; j < n; j++ {
+			if j < 4 {
+				a.Add(r, j)
+			} else {
+				b.Add(r, j)
+			}
+		}
+		a.Merge(r, b)
+		for j := n; j < 2*n; j++ {
+			a.Add(r, j%n)
+		}
+		if a.Count() != 2*n {
+			t.Fatalf("got count %v instead of %v", a.Count(), 2*n)
+		}
+		for _, x := range a.Sample() {
+			counts[x]++
+		}
+	}
+	expected := make([]float64, n)
+	for i := range expected {
+		expected[i] = 2 * trials * k / (2 * n)
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestWeightedReservoir_Distribution(t *testing.T) {
+	const trials = 1 << 17
+	weights := []float64{1, 2, 0, 3, 4}
+	r := rand.New(1)
+	for _, merge := range []bool{false, true} {
+		counts := make([]int, len(weights)*len(weights))
+		for i := 0; i < trials; i++ {
+			a := rand.NewWeightedReservoir[int](2)
+			b := rand.NewWeightedReservoir[int](2)
+			for j, w := range weights {
+				if merge && j%2 == 0 {
+					b.Add(r, j, w)
+				} else {
+					a.Add(r, j, w)
+				}
+			}
+			a.Merge(r, b)
+			s := a.Sample()
+			if len(s) != 2 {
+				t.Fatalf("got %v items instead of 2", len(s))
+			}
+			if s[0] > s[1] {
+				s[0], s[1] = s[1], s[0]
+			}
+			counts[s[0]*len(weights)+s[1]]++
+		}
+		expected := make([]float64, len(counts))
+		for i, wi := range weights {
+			for j, wj := range weights {
+				if i < j {
+					expected[i*len(weights)+j] = trials * (wi/10*wj/(10-wi) + wj/10*wi/(10-wj))
+				}
+			}
+		}
+		checkChiSquared(t, counts, expected)
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import "math"
+
+const (
+	specEps    = 0x1p-53
+	specTiny   = 0x1p-1000 // guards against division by zero in continued fractions
+	specMaxIts = 10000
+)
+
+// specIts returns the iteration limit for series and continued fractions with parameter a,
+// which need O(sqrt(a)) terms to converge when x is close to a.
+func specIts(a float64) int {
+	return specMaxIts + int(math.Min(16*math.Sqrt(a), 1<<30))
+}
+
+func lgamma(x float64) float64 {
+	l, _ := math.Lgamma(x)
+	return l
+}
+
+// lbeta returns the logarithm of the beta function B(a, b).
+func lbeta(a float64, b float64) float64 {
+	return lgamma(a) + lgamma(b) - lgamma(a+b)
+}
+
+// stirlerr returns log(n!) - log(sqrt(2πn) * (n/e)^n), the error of Stirling's approximation.
+func stirlerr(n float64) float64 {
+	if n <= 15 {
+		return lgamma(n+1) - (n+0.5)*math.Log(n) + n - math.Log(2*math.Pi)/2
+	}
+	n2 := n * n
+	return (1.0/12 - (1.0/360-(1.0/1260-(1.0/1680-1.0/1188/n2)/n2)/n2)/n2) / n
+}
+
+// bd0 returns x*log(x/m) + m - x, without cancellation when x is close to m.
+func bd0(x float64, m float64) float64 {
+	if math.Abs(x-m) >= 0.1*(x+m) {
+		return x*math.Log(x/m) + m - x
+	}
+	v := (x - m) / (x + m)
+	s := (x - m) * v
+	ej := 2 * x * v
+	v2 := v * v
+	for j := 3.0; ; j += 2 {
+		ej *= v2
+		s1 := s + ej/j
+		if s1 == s {
+			return s
+		}
+		s = s1
+	}
+}
+
+// dpoisRaw returns m^x * e^(-m) / Γ(x+1) for x >= 0, using the saddle point expansion by C. Loader,
+// which stays accurate for large x and m where the direct formula suffers from cancellation.
+func dpoisRaw(x float64, m float64) float64 {
+	if x == 0 {
+		return math.Exp(-m)
+	}
+	if m == 0 {
+		return 0
+	}
+	return math.Exp(-stirlerr(x)-bd0(x, m)) / math.Sqrt(2*math.Pi*x)
+}
+
+// dbinomRaw returns Γ(n+1) / (Γ(x+1) * Γ(n-x+1)) * p^x * q^(n-x) for q = 1-p and x in [0, n],
+// using the saddle point expansion by C. Loader.
+func dbinomRaw(x float64, n float64, p float64, q float64) float64 {
+	switch {
+	case p == 0:
+		if x == 0 {
+			return 1
+		}
+		return 0
+	case q == 0:
+		if x == n {
+			return 1
+		}
+		return 0
+	case x == 0:
+		if p < 0.1 {
+			return math.Exp(-bd0(n, n*q) - n*p)
+		}
+		return math.Exp(n * math.Log(q))
+	case x == n:
+		if q < 0.1 {
+			return math.Exp(-bd0(n, n*p) - n*q)
+		}
+		return math.Exp(n * math.Log(p))
+	}
+	lc := stirlerr(n) - stirlerr(x) - stirlerr(n-x) - bd0(x, n*p) - bd0(n-x, n*q)
+	return math.Exp(lc) * math.Sqrt(n/(2*math.Pi*x*(n-x)))
+}
+
+// gammaIncP returns the regularized lower incomplete gamma function P(a, x).
+func gammaIncP(a float64, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x < a+1 {
+		return gammaSeries(a, x)
+	}
+	return 1 - gammaFraction(a, x)
+}
+
+// gammaIncQ returns the regularized upper incomplete gamma function Q(a, x) = 1 - P(a, x).
+func gammaIncQ(a float64, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaFraction(a, x)
+}
+
+// gammaSeries evaluates P(a, x) by its series representation, which converges quickly for x < a+1.
+func gammaSeries(a float64, x float64) float64 {
+	ap := a
+	del := 1 / a
+	sum := del
+	for i, its := 0, specIts(a); i < its; i++ {
+		ap++
+		del *= x / ap
+		sum += del
+		if math.Abs(del) < math.Abs(sum)*specEps {
+			break
+		}
+	}
+	return sum * a * dpoisRaw(a, x)
+}
+
+// gammaFraction evaluates Q(a, x) by its continued fraction (modified Lentz's method), which converges quickly for x >= a+1.
+func gammaFraction(a float64, x float64) float64 {
+	if math.IsInf(x, 1) {
+		return 0
+	}
+	b := x + 1 - a
+	c := 1 / specTiny
+	d := 1 / b
+	h := d
+	for i, its := 1, specIts(a); i < its; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < specTiny {
+			d = specTiny
+		}
+		c = b + an/c
+		if math.Abs(c) < specTiny {
+			c = specTiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < specEps {
+			break
+		}
+	}
+	return a * dpoisRaw(a, x) * h
+}
+
+// betaInc returns the regularized incomplete beta function I_x(a, b).
+func betaInc(a float64, b float64, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	// x^a * (1-x)^b / B(a, b) = bt * a * b / (a+b)
+	bt := dbinomRaw(a, a+b, x, 1-x)
+	if x < (a+1)/(a+b+2) {
+		return bt * b / (a + b) * betaFraction(a, b, x)
+	}
+	return 1 - bt*a/(a+b)*betaFraction(b, a, 1-x)
+}
+
+// betaFraction evaluates the continued fraction for I_x(a, b) (modified Lentz's method).
+func betaFraction(a float64, b float64, x float64) float64 {
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < specTiny {
+		d = specTiny
+	}
+	d = 1 / d
+	h := d
+	for m, its := 1, specIts(math.Max(a, b)); m < its; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < specTiny {
+			d = specTiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < specTiny {
+			c = specTiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < specTiny {
+			d = specTiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < specTiny {
+			c = specTiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < specEps {
+			break
+		}
+	}
+	return h
+}
+
+// gaussLegendre returns the nodes and weights of the n-point Gauss-Legendre quadrature on [-1, 1].
+func gaussLegendre(n int) ([]float64, []float64) {
+	x := make([]float64, n)
+	w := make([]float64, n)
+	for i := 0; i < (n+1)/2; i++ {
+		// Newton's method on the Legendre polynomial P_n, starting from an approximation of its root
+		z := math.Cos(math.Pi * (float64(i) + 0.75) / (float64(n) + 0.5))
+		var dp float64
+		for it := 0; it < 100; it++ {
+			p1, p2 := 1.0, 0.0
+			for j := 1; j <= n; j++ {
+				p1, p2 = ((2*float64(j)-1)*z*p1-(float64(j)-1)*p2)/float64(j), p1
+			}
+			dp = float64(n) * (z*p1 - p2) / (z*z - 1)
+			dz := p1 / dp
+			z -= dz
+			if math.Abs(dz) < specEps {
+				break
+			}
+		}
+		x[i], x[n-1-i] = -z, z
+		w[i] = 2 / ((1 - z*z) * dp * dp)
+		w[n-1-i] = w[i]
+	}
+	return x, w
+}
+
+// invertCDF returns the smallest x in [lo, hi] (up to floating-point precision) such that cdf(x) >= p,
+// for non-decreasing cdf. Infinite bounds are replaced by a bracket found by expanding from guess.
+func invertCDF(cdf func(float64) float64, p float64, lo float64, hi float64, guess float64) float64 {
+	if math.IsInf(hi, 1) {
+		step := math.Max(math.Abs(guess), 1)
+		h := guess + step
+		for cdf(h) < p && !math.IsInf(h, 1) {
+			step *= 2
+			h += step
+		}
+		hi = h
+	}
+	if math.IsInf(lo, -1) {
+		step := math.Max(math.Abs(guess), 1)
+		l := guess - step
+		for cdf(l) >= p && !math.IsInf(l, -1) {
+			step *= 2
+			l -= step
+		}
+		lo = l
+	}
+	for i := 0; i < specMaxIts; i++ {
+		mid := lo + (hi-lo)/2
+		if lo > 0 && hi/lo > 4 {
+			mid = math.Sqrt(lo) * math.Sqrt(hi) // bisect in log space over wide positive ranges
+		}
+		if mid <= lo || mid >= hi {
+			break
+		}
+		if cdf(mid) >= p {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
@@ -19,6 +19,8 @@ import (
  * see https://github.com/kokizzu/rand/issues/3
  */
 
+//go:generate go run ./misc/ziggurat -dist exp -check std_exp.go
+
 const (
 	re = 7.69711747013104972
 )
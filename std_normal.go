@@ -19,6 +19,8 @@ import (
  * see https://github.com/kokizzu/rand/issues/3
  */
 
+//go:generate go run ./misc/ziggurat -dist normal -check std_normal.go
+
 const (
 	rn = 3.6541528853610088
 )
@@ -9,9 +9,16 @@
 
 package rand
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // A Zipf generates Zipf distributed variates.
+//
+// A Zipf returned by [NewZipfDistribution] is immutable, and can be shared between goroutines using distinct
+// generators with [Zipf.Sample]. [NewZipf] is a thin wrapper that binds it to a generator for [Zipf.Uint64],
+// which makes it unsafe for concurrent use.
 type Zipf struct {
 	r            *Rand
 	imax         float64
@@ -22,48 +29,82 @@ type Zipf struct {
 	oneminusQinv float64
 	hxm          float64
 	hx0minusHxm  float64
+	imaxInt      uint64
+	norm         float64 // sum of (v + k) ** (-s) over k ∈ [0, imax]
 }
 
 func (z *Zipf) h(x float64) float64 {
+	if z.q <= 1 {
+		return z.hSmall(x)
+	}
 	return math.Exp(z.oneminusQ*math.Log(z.v+x)) * z.oneminusQinv
 }
 
 func (z *Zipf) hinv(x float64) float64 {
+	if z.q <= 1 {
+		return z.hinvSmall(x)
+	}
 	return math.Exp(z.oneminusQinv*math.Log(z.oneminusQ*x)) - z.v
 }
 
 // NewZipf returns a Zipf variate generator.
 // The generator generates values k ∈ [0, imax]
 // such that P(k) is proportional to (v + k) ** (-s).
-// Requirements: s > 1 and v >= 1.
+// Requirements: s > 0 and v >= 1. NewZipf returns nil if they are not met;
+// use [NewZipfDistribution] to get an error instead.
 func NewZipf(r *Rand, s float64, v float64, imax uint64) *Zipf {
-	z := new(Zipf)
-	if s <= 1.0 || v < 1 {
+	z, err := NewZipfDistribution(s, v, imax)
+	if err != nil {
 		return nil
 	}
 	z.r = r
-	z.imax = float64(imax)
+	return z
+}
+
+// NewZipfDistribution returns the Zipf distribution of values k ∈ [0, imax]
+// such that P(k) is proportional to (v + k) ** (-s).
+// It returns an error unless s > 0 and v >= 1.
+// The returned Zipf is not bound to a generator: use [Zipf.Sample] instead of [Zipf.Uint64].
+func NewZipfDistribution(s float64, v float64, imax uint64) (*Zipf, error) {
+	if !(s > 0) || math.IsInf(s, 1) || !(v >= 1) || math.IsInf(v, 1) {
+		return nil, fmt.Errorf("rand: invalid Zipf distribution parameters s=%v v=%v", s, v)
+	}
+	z := new(Zipf)
 	z.v = v
 	z.q = s
 	z.oneminusQ = 1.0 - z.q
 	z.oneminusQinv = 1.0 / z.oneminusQ
+	z.s = 1 - z.hinv(z.h(1.5)-math.Exp(-z.q*math.Log(z.v+1.0)))
+	z.setImax(imax)
+	return z, nil
+}
+
+func (z *Zipf) setImax(imax uint64) {
+	z.imax = float64(imax)
 	z.hxm = z.h(z.imax + 0.5)
 	z.hx0minusHxm = z.h(0.5) - math.Exp(math.Log(z.v)*(-z.q)) - z.hxm
-	z.s = 1 - z.hinv(z.h(1.5)-math.Exp(-z.q*math.Log(z.v+1.0)))
-	return z
+	z.initDistribution(imax)
 }
 
 // Uint64 returns a value drawn from the Zipf distribution described
-// by the Zipf object.
+// by the Zipf object, using the generator it was created with by [NewZipf].
 func (z *Zipf) Uint64() uint64 {
 	if z == nil {
 		panic("rand: nil Zipf")
 	}
+	if z.r == nil {
+		panic("rand: Zipf without generator, use Sample instead")
+	}
+	return z.Sample(z.r)
+}
+
+// Sample returns a value drawn from the Zipf distribution, using r as the source of randomness.
+func (z *Zipf) Sample(r *Rand) uint64 {
 	k := 0.0
 
 	for {
-		r := z.r.Float64() // r on [0,1]
-		ur := z.hxm + r*z.hx0minusHxm
+		u := r.Float64() // u on [0,1]
+		ur := z.hxm + u*z.hx0minusHxm
 		x := z.hinv(ur)
 		k = math.Floor(x + 0.5)
 		if k-x <= z.s {
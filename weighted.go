@@ -0,0 +1,208 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Weighted samples indices with probabilities proportional to a fixed set of weights.
+//
+// Weighted uses Vose's variant of the alias method: construction takes O(n) time,
+// and sampling takes O(1) time regardless of the number of weights.
+type Weighted struct {
+	prob  []float64
+	alias []int
+	p     []float64
+}
+
+// NewWeighted returns a sampler of indices in [0, len(weights)), that chooses i with
+// probability weights[i] / sum(weights). NewWeighted returns an error if weights is empty,
+// if any of the weights is negative, infinite or NaN, or if all weights are zero.
+func NewWeighted(weights []float64) (*Weighted, error) {
+	p, err := normalizeWeights(weights)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(p)
+	w := &Weighted{
+		prob:  make([]float64, n),
+		alias: make([]int, n),
+		p:     p,
+	}
+	scaled := make([]float64, n)
+	small := make([]int, 0, n)
+	large := make([]int, 0, n)
+	for i, pi := range p {
+		scaled[i] = pi * float64(n)
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		l := small[len(small)-1]
+		small = small[:len(small)-1]
+		g := large[len(large)-1]
+		large = large[:len(large)-1]
+		w.prob[l] = scaled[l]
+		w.alias[l] = g
+		scaled[g] = (scaled[g] + scaled[l]) - 1 // more stable than scaled[g] - (1 - scaled[l])
+		if scaled[g] < 1 {
+			small = append(small, g)
+		} else {
+			large = append(large, g)
+		}
+	}
+	// whatever is left (only due to rounding errors for small) fills its column completely
+	for _, g := range large {
+		w.prob[g] = 1
+		w.alias[g] = g
+	}
+	for _, l := range small {
+		w.prob[l] = 1
+		w.alias[l] = l
+	}
+	return w, nil
+}
+
+func validWeight(w float64) bool {
+	return w >= 0 && !math.IsInf(w, 1)
+}
+
+// normalizeWeights validates weights and returns them scaled to sum to 1.
+func normalizeWeights(weights []float64) ([]float64, error) {
+	if len(weights) == 0 {
+		return nil, errors.New("rand: no weights")
+	}
+	max := 0.0
+	for i, w := range weights {
+		if !validWeight(w) {
+			return nil, fmt.Errorf("rand: invalid weight %v at index %v", w, i)
+		}
+		if w > max {
+			max = w
+		}
+	}
+	if max == 0 {
+		return nil, errors.New("rand: all weights are zero")
+	}
+	// dividing by max first guarantees that the sum does not overflow
+	p := make([]float64, len(weights))
+	sum := 0.0
+	for i, w := range weights {
+		p[i] = w / max
+		sum += p[i]
+	}
+	for i := range p {
+		p[i] /= sum
+	}
+	return p, nil
+}
+
+// Len returns the number of weights w was constructed from.
+func (w *Weighted) Len() int {
+	return len(w.p)
+}
+
+// Probabilities returns, as a new slice, the probability of choosing each index.
+func (w *Weighted) Probabilities() []float64 {
+	return append([]float64(nil), w.p...)
+}
+
+// Sample returns a pseudo-random index in [0, w.Len()), chosen with probability proportional to its weight.
+// Indices with zero weight are never returned.
+func (w *Weighted) Sample(r *Rand) int {
+	i := int(r.Uint64n(uint64(len(w.prob))))
+	if r.Float64() < w.prob[i] {
+		return i
+	}
+	return w.alias[i]
+}
+
+// WeightedSampleK returns, as a slice of k ints, distinct pseudo-random indices in [0, len(weights)),
+// chosen one after another with probability proportional to their weight among the indices not chosen yet.
+// When all indices with non-zero weight have been chosen, indices with zero weight follow in uniformly
+// random order. WeightedSampleK panics if k < 0, if k > len(weights), or if any of the weights
+// is negative, infinite or NaN.
+//
+// WeightedSampleK uses exponential keys (Efraimidis and Spirakis) and takes O(n log k) time.
+func WeightedSampleK(r *Rand, weights []float64, k int) []int {
+	if k < 0 || k > len(weights) {
+		panic("invalid argument to WeightedSampleK")
+	}
+	return weightedOrder(r, weights, k, "WeightedSampleK")
+}
+
+type weightedKey struct {
+	key float64
+	ix  int
+}
+
+// weightedKeyHeap is a max-heap of keys, with ties broken by index.
+type weightedKeyHeap []weightedKey
+
+func (h weightedKeyHeap) Len() int { return len(h) }
+func (h weightedKeyHeap) Less(i, j int) bool {
+	return h[i].key > h[j].key || (h[i].key == h[j].key && h[i].ix > h[j].ix)
+}
+func (h weightedKeyHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *weightedKeyHeap) Push(x interface{}) { *h = append(*h, x.(weightedKey)) }
+func (h *weightedKeyHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// weightedOrder returns the first k indices of a weighted random order of weights.
+//
+// Each index with positive weight w gets the key E/w, where E is a standard exponential variate;
+// ordering by ascending key is equivalent to choosing indices one after another proportionally
+// to their weight. Keys are compared in log space, where they neither overflow nor underflow
+// for any ratio of weights. Keys that are equal (which is extremely unlikely) are ordered by index.
+func weightedOrder(r *Rand, weights []float64, k int, name string) []int {
+	for _, w := range weights {
+		if !validWeight(w) {
+			panic("invalid argument to " + name)
+		}
+	}
+
+	h := make(weightedKeyHeap, 0, k)
+	var zeros []int
+	for i, w := range weights {
+		if w == 0 {
+			zeros = append(zeros, i)
+			continue
+		}
+		key := math.Log(r.ExpFloat64()) - math.Log(w)
+		if len(h) < k {
+			heap.Push(&h, weightedKey{key, i})
+		} else if k > 0 && key < h[0].key {
+			h[0] = weightedKey{key, i}
+			heap.Fix(&h, 0)
+		}
+	}
+	sort.Sort(sort.Reverse(h))
+
+	p := make([]int, len(h), k)
+	for i, e := range h {
+		p[i] = e.ix
+	}
+	for i := 0; len(p) < k; i++ {
+		j := i + int(r.Uint64n(uint64(len(zeros)-i)))
+		zeros[i], zeros[j] = zeros[j], zeros[i]
+		p = append(p, zeros[i])
+	}
+	return p
+}
@@ -0,0 +1,199 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const dynamicWeightedResidue = 1e-9 // negative results of Add down to this times the old weight are rounding errors
+
+// DynamicWeighted samples indices with probabilities proportional to weights that can change over time.
+//
+// DynamicWeighted is backed by a sum tree: updating a weight and sampling both take O(log n) time.
+// Every sum is recomputed from its children on update (instead of being adjusted by the difference,
+// like in a Fenwick tree), so rounding errors do not accumulate regardless of the number of updates.
+//
+// DynamicWeighted is not safe for concurrent use.
+type DynamicWeighted struct {
+	tree    []float64 // tree[1] is the root, leaves start at tree[size]
+	size    int       // number of leaves, always a power of two
+	n       int
+	nonzero int
+}
+
+// NewDynamicWeighted returns a sampler of indices in [0, len(weights)), that chooses i with
+// probability weights[i] / sum(weights). NewDynamicWeighted returns an error if any of the weights
+// is negative, infinite or NaN, or if the sum of weights is infinite. Unlike [NewWeighted],
+// NewDynamicWeighted accepts empty or all-zero weights, since they can be updated later.
+func NewDynamicWeighted(weights []float64) (*DynamicWeighted, error) {
+	for i, w := range weights {
+		if !validWeight(w) {
+			return nil, fmt.Errorf("rand: invalid weight %v at index %v", w, i)
+		}
+	}
+	d := &DynamicWeighted{tree: make([]float64, 2), size: 1}
+	d.grow(len(weights))
+	for i, w := range weights {
+		d.tree[d.size+i] = w
+		if w != 0 {
+			d.nonzero++
+		}
+	}
+	d.rebuild()
+	if math.IsInf(d.Total(), 1) {
+		return nil, errors.New("rand: sum of weights overflows")
+	}
+	return d, nil
+}
+
+// grow makes room for at least n weights.
+func (d *DynamicWeighted) grow(n int) {
+	if n <= d.size {
+		d.n = n
+		return
+	}
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	tree := make([]float64, 2*size)
+	for i := 0; i < d.n; i++ {
+		tree[size+i] = d.tree[d.size+i]
+	}
+	d.tree, d.size, d.n = tree, size, n
+	d.rebuild()
+}
+
+func (d *DynamicWeighted) rebuild() {
+	for i := d.size - 1; i >= 1; i-- {
+		d.tree[i] = d.tree[2*i] + d.tree[2*i+1]
+	}
+}
+
+// Len returns the number of weights.
+func (d *DynamicWeighted) Len() int {
+	return d.n
+}
+
+// Total returns the sum of all weights.
+func (d *DynamicWeighted) Total() float64 {
+	return d.tree[1]
+}
+
+// Weight returns the weight of index i. It panics if i is out of range.
+func (d *DynamicWeighted) Weight(i int) float64 {
+	if i < 0 || i >= d.n {
+		panic("invalid argument to Weight")
+	}
+	return d.tree[d.size+i]
+}
+
+// Set sets the weight of index i to w. If i >= d.Len(), the sampler grows to i+1 weights,
+// with all new weights except w equal to zero. Set panics if i < 0, if w is negative,
+// infinite or NaN, or if the new sum of weights is infinite.
+func (d *DynamicWeighted) Set(i int, w float64) {
+	if i < 0 || !validWeight(w) {
+		panic("invalid argument to Set")
+	}
+	if i >= d.n {
+		// the new leaves are zero, so the sum of the grown tree is exactly Total() + w
+		if math.IsInf(d.Total()+w, 1) {
+			panic("invalid argument to Set")
+		}
+		d.grow(i + 1)
+		d.set(i, w)
+		return
+	}
+	old := d.tree[d.size+i]
+	d.set(i, w)
+	if math.IsInf(d.Total(), 1) {
+		d.set(i, old)
+		panic("invalid argument to Set")
+	}
+}
+
+// Add adds delta to the weight of index i. It panics if i is out of range,
+// or if the resulting weight would be invalid according to [DynamicWeighted.Set].
+// A negative result within rounding error of zero, e.g. from adding back the negated sum of earlier deltas,
+// is set to zero.
+func (d *DynamicWeighted) Add(i int, delta float64) {
+	old := d.Weight(i)
+	w := old + delta
+	if w < 0 && -w <= dynamicWeightedResidue*old {
+		w = 0
+	}
+	d.Set(i, w)
+}
+
+// Remove sets the weight of index i to zero, so that i is never sampled until its weight is set again.
+// Remove panics if i is out of range.
+func (d *DynamicWeighted) Remove(i int) {
+	if i < 0 || i >= d.n {
+		panic("invalid argument to Remove")
+	}
+	d.set(i, 0)
+}
+
+func (d *DynamicWeighted) set(i int, w float64) {
+	j := d.size + i
+	if (d.tree[j] == 0) != (w == 0) {
+		if w == 0 {
+			d.nonzero--
+		} else {
+			d.nonzero++
+		}
+	}
+	d.tree[j] = w
+	for j /= 2; j >= 1; j /= 2 {
+		d.tree[j] = d.tree[2*j] + d.tree[2*j+1]
+	}
+}
+
+// Sample returns a pseudo-random index in [0, d.Len()), chosen with probability proportional to its weight.
+// Indices with zero weight are never returned. Sample panics if all weights are zero.
+func (d *DynamicWeighted) Sample(r *Rand) int {
+	if d.nonzero == 0 {
+		panic("rand: sampling from DynamicWeighted with all weights zero")
+	}
+	u := r.Float64() * d.tree[1]
+	i := 1
+	for i < d.size {
+		// never descend into a subtree with zero sum, even if rounding errors suggest so
+		if l := d.tree[2*i]; u < l || d.tree[2*i+1] == 0 {
+			i = 2 * i
+		} else {
+			u -= l
+			i = 2*i + 1
+		}
+	}
+	return i - d.size
+}
+
+// SampleK returns, as a slice of k ints, distinct pseudo-random indices chosen one after another
+// with probability proportional to their weight among the indices not chosen yet (that is, sampled
+// without replacement). The weights are the same after SampleK returns. SampleK panics if k < 0 or
+// if k is greater than the number of indices with non-zero weight.
+func (d *DynamicWeighted) SampleK(r *Rand, k int) []int {
+	if k < 0 || k > d.nonzero {
+		panic("invalid argument to SampleK")
+	}
+	p := make([]int, k)
+	w := make([]float64, k)
+	for j := range p {
+		i := d.Sample(r)
+		p[j], w[j] = i, d.tree[d.size+i]
+		d.set(i, 0)
+	}
+	// restoring the same leaves recomputes exactly the same sums
+	for j := len(p) - 1; j >= 0; j-- {
+		d.set(p[j], w[j])
+	}
+	return p
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/kokizzu/rand"
+)
+
+func BenchmarkDynamicWeighted_Sample(b *testing.B) {
+	weights := make([]float64, small)
+	for i := range weights {
+		weights[i] = float64(i)
+	}
+	d, _ := rand.NewDynamicWeighted(weights)
+	r := rand.New(1)
+	var s int
+	for i := 0; i < b.N; i++ {
+		s = d.Sample(r)
+	}
+	sinkInt = s
+}
+
+func BenchmarkDynamicWeighted_Set(b *testing.B) {
+	d, _ := rand.NewDynamicWeighted(make([]float64, small))
+	for i := 0; i < b.N; i++ {
+		d.Set(i%small, float64(i))
+	}
+}
+
+func TestNewDynamicWeighted_Invalid(t *testing.T) {
+	for _, weights := range [][]float64{
+		{1, -1},
+		{1, math.NaN()},
+		{1, math.Inf(1)},
+		{math.MaxFloat64, math.MaxFloat64},
+	} {
+		if _, err := rand.NewDynamicWeighted(weights); err == nil {
+			t.Errorf("got no error for weights %v", weights)
+		}
+	}
+}
+
+func TestDynamicWeighted_Invalid(t *testing.T) {
+	d, _ := rand.NewDynamicWeighted([]float64{math.MaxFloat64, 1})
+	for _, c := range []struct {
+		i int
+		w float64
+	}{{-1, 1}, {0, -1}, {0, math.NaN()}, {0, math.Inf(1)}, {1, math.MaxFloat64}, {5, math.MaxFloat64}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("got no panic for index %v, weight %v", c.i, c.w)
+				}
+			}()
+			d.Set(c.i, c.w)
+		}()
+		if d.Len() != 2 || d.Weight(0) != math.MaxFloat64 || d.Weight(1) != 1 || d.Total() != math.MaxFloat64 {
+			t.Errorf("got length %v and weights %v, %v after invalid Set", d.Len(), d.Weight(0), d.Weight(1))
+		}
+	}
+}
+
+func TestDynamicWeighted_AddResidue(t *testing.T) {
+	d, _ := rand.NewDynamicWeighted([]float64{0.3, 1})
+	d.Add(0, -0.1)
+	d.Add(0, -0.2) // 0.3 - 0.1 - 0.2 is slightly negative
+	if w := d.Weight(0); w != 0 {
+		t.Errorf("got weight %v instead of 0", w)
+	}
+	if ix := d.Sample(rand.New(1)); ix != 1 {
+		t.Errorf("sampled index %v with zero weight", ix)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("got no panic for a negative weight")
+			}
+		}()
+		d.Add(1, -1.5)
+	}()
+}
+
+func TestDynamicWeighted_Model(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		weight := rapid.SampledFrom([]float64{0, 0.1, 1, 2.5, 100})
+		model := rapid.SliceOf(weight).Draw(t, "weights").([]float64)
+		d, err := rand.NewDynamicWeighted(model)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ops := rapid.IntRange(0, 100).Draw(t, "ops").(int)
+		for op := 0; op < ops; op++ {
+			i := rapid.IntRange(0, len(model)+2).Draw(t, "i").(int)
+			switch rapid.IntRange(0, 3).Draw(t, "op").(int) {
+			case 0:
+				w := weight.Draw(t, "w").(float64)
+				d.Set(i, w)
+				for len(model) <= i {
+					model = append(model, 0)
+				}
+				model[i] = w
+			case 1:
+				if i < len(model) {
+					delta := weight.Draw(t, "delta").(float64)
+					d.Add(i, delta)
+					model[i] += delta
+				}
+			case 2:
+				if i < len(model) {
+					d.Remove(i)
+					model[i] = 0
+				}
+			case 3:
+				nonzero := 0
+				for _, w := range model {
+					if w != 0 {
+						nonzero++
+					}
+				}
+				if nonzero == 0 {
+					continue
+				}
+				k := rapid.IntRange(0, nonzero).Draw(t, "k").(int)
+				seen := map[int]bool{}
+				for _, ix := range d.SampleK(r, k) {
+					if model[ix] == 0 || seen[ix] {
+						t.Fatalf("got zero-weight or duplicate index %v", ix)
+					}
+					seen[ix] = true
+				}
+			}
+		}
+		if d.Len() != len(model) {
+			t.Fatalf("got length %v instead of %v", d.Len(), len(model))
+		}
+		total := 0.0
+		for i, w := range model {
+			if d.Weight(i) != w {
+				t.Fatalf("got weight %v instead of %v at index %v", d.Weight(i), w, i)
+			}
+			total += w
+		}
+		if math.Abs(d.Total()-total) > 1e-9*total {
+			t.Fatalf("got total %v instead of %v", d.Total(), total)
+		}
+		if total > 0 {
+			if ix := d.Sample(r); model[ix] == 0 {
+				t.Fatalf("sampled index %v with zero weight", ix)
+			}
+		}
+	})
+}
+
+func TestDynamicWeighted_Distribution(t *testing.T) {
+	const n = 1 << 20
+	d, err := rand.NewDynamicWeighted([]float64{1, 5, 2})
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	d.Set(5, 4)
+	d.Add(1, -2)
+	d.Remove(2)
+	weights := []float64{1, 3, 0, 0, 0, 4}
+	r := rand.New(1)
+	counts := make([]int, len(weights))
+	for i := 0; i < n; i++ {
+		counts[d.Sample(r)]++
+	}
+	expected := make([]float64, len(weights))
+	for i, w := range weights {
+		expected[i] = n * w / d.Total()
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestDynamicWeighted_SampleK(t *testing.T) {
+	const n = 1 << 18
+	weights := []float64{1, 2, 3, 4}
+	d, err := rand.NewDynamicWeighted(weights)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	r := rand.New(1)
+	counts := make([]int, len(weights)*len(weights))
+	for i := 0; i < n; i++ {
+		p := d.SampleK(r, 2)
+		counts[p[0]*len(weights)+p[1]]++
+	}
+	expected := make([]float64, len(counts))
+	for i, wi := range weights {
+		for j, wj := range weights {
+			if i != j {
+				expected[i*len(weights)+j] = n * wi / 10 * wj / (10 - wi)
+			}
+		}
+	}
+	checkChiSquared(t, counts, expected)
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build go1.18
+
+package rand
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// NewWeightedInts is like [NewWeighted], but accepts integer weights.
+func NewWeightedInts[T constraints.Integer](weights []T) (*Weighted, error) {
+	f := make([]float64, len(weights))
+	for i, w := range weights {
+		f[i] = float64(w)
+	}
+	return NewWeighted(f)
+}
+
+// WeightedChoice samples items with probabilities proportional to their weights, using [Weighted].
+type WeightedChoice[T any] struct {
+	items []T
+	w     *Weighted
+}
+
+// NewWeightedChoice returns a sampler that chooses items[i] with probability weights[i] / sum(weights).
+// NewWeightedChoice returns an error if the lengths of items and weights differ,
+// or if weights are invalid according to [NewWeighted].
+func NewWeightedChoice[T any](items []T, weights []float64) (*WeightedChoice[T], error) {
+	if len(items) != len(weights) {
+		return nil, fmt.Errorf("rand: got %v items but %v weights", len(items), len(weights))
+	}
+	w, err := NewWeighted(weights)
+	if err != nil {
+		return nil, err
+	}
+	return &WeightedChoice[T]{items: append([]T(nil), items...), w: w}, nil
+}
+
+// Probabilities returns, as a new slice, the probability of choosing each item.
+func (c *WeightedChoice[T]) Probabilities() []float64 {
+	return c.w.Probabilities()
+}
+
+// Sample returns a pseudo-random item, chosen with probability proportional to its weight.
+func (c *WeightedChoice[T]) Sample(r *Rand) T {
+	return c.items[c.w.Sample(r)]
+}
+
+// WeightedShuffle pseudo-randomizes the order of the elements of s, so that elements with higher weight
+// tend to come first: the first element is chosen with probability proportional to its weight, the second one
+// with probability proportional to its weight among the remaining elements, and so on. Elements with zero weight
+// come last, in uniformly random order. weight is called exactly once for every element of s.
+// WeightedShuffle panics if any of the weights is negative, infinite or NaN.
+func WeightedShuffle[S ~[]E, E any](r *Rand, s S, weight func(E) float64) {
+	weights := make([]float64, len(s))
+	for i, e := range s {
+		weights[i] = weight(e)
+	}
+	c := append(S(nil), s...)
+	for i, j := range weightedOrder(r, weights, len(s), "WeightedShuffle") {
+		s[i] = c[j]
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build go1.18
+
+package rand_test
+
+import (
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+func TestNewWeightedInts(t *testing.T) {
+	if _, err := rand.NewWeightedInts([]int{1, -1}); err == nil {
+		t.Fatalf("got no error for negative weight")
+	}
+	w, err := rand.NewWeightedInts([]uint8{1, 0, 3})
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	p := w.Probabilities()
+	if p[0] != 0.25 || p[1] != 0 || p[2] != 0.75 {
+		t.Fatalf("got probabilities %v", p)
+	}
+}
+
+func TestWeightedChoice(t *testing.T) {
+	const n = 1 << 18
+	if _, err := rand.NewWeightedChoice([]string{"a"}, []float64{1, 2}); err == nil {
+		t.Fatalf("got no error for mismatched lengths")
+	}
+	items := []string{"a", "b", "c"}
+	c, err := rand.NewWeightedChoice(items, []float64{1, 0, 3})
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	r := rand.New(1)
+	counts := make([]int, len(items))
+	for i := 0; i < n; i++ {
+		switch c.Sample(r) {
+		case "a":
+			counts[0]++
+		case "b":
+			counts[1]++
+		case "c":
+			counts[2]++
+		}
+	}
+	checkChiSquared(t, counts, []float64{n / 4, 0, n * 3 / 4})
+}
+
+func TestWeightedShuffle(t *testing.T) {
+	const n = 1 << 16
+	r := rand.New(1)
+	counts := make([]int, 4)
+	for i := 0; i < n; i++ {
+		s := []string{"a", "bb", "", "cccc"}
+		rand.WeightedShuffle(r, s, func(e string) float64 { return float64(len(e)) })
+		if s[3] != "" {
+			t.Fatalf("element with zero weight is not last: %q", s)
+		}
+		seen := map[string]bool{}
+		for _, e := range s {
+			seen[e] = true
+		}
+		if len(seen) != 4 {
+			t.Fatalf("shuffle lost elements: %q", s)
+		}
+		counts[len(s[0])-1]++
+	}
+	checkChiSquared(t, counts, []float64{n / 7, n * 2 / 7, 0, n * 4 / 7})
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/kokizzu/rand"
+)
+
+func BenchmarkWeighted_Sample(b *testing.B) {
+	weights := make([]float64, small)
+	for i := range weights {
+		weights[i] = float64(i)
+	}
+	w, _ := rand.NewWeighted(weights)
+	r := rand.New(1)
+	var s int
+	for i := 0; i < b.N; i++ {
+		s = w.Sample(r)
+	}
+	sinkInt = s
+}
+
+func BenchmarkWeightedSampleK(b *testing.B) {
+	weights := make([]float64, small)
+	for i := range weights {
+		weights[i] = float64(i)
+	}
+	r := rand.New(1)
+	var s []int
+	for i := 0; i < b.N; i++ {
+		s = rand.WeightedSampleK(r, weights, small/4)
+	}
+	sinkInt = s[0]
+}
+
+func TestNewWeighted_Invalid(t *testing.T) {
+	for _, weights := range [][]float64{
+		nil,
+		{0},
+		{0, 0, 0},
+		{1, -1},
+		{1, math.NaN()},
+		{1, math.Inf(1)},
+	} {
+		if _, err := rand.NewWeighted(weights); err == nil {
+			t.Errorf("got no error for weights %v", weights)
+		}
+	}
+}
+
+func TestWeighted_Probabilities(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := rapid.SliceOfN(rapid.Float64Range(0, math.MaxFloat64), 1, -1).Draw(t, "weights").([]float64)
+		w, err := rand.NewWeighted(weights)
+		if err != nil {
+			for _, x := range weights {
+				if x != 0 {
+					t.Fatalf("got unexpected error: %v", err)
+				}
+			}
+			t.Skip("all weights are zero")
+		}
+		p := w.Probabilities()
+		if len(p) != len(weights) || w.Len() != len(weights) {
+			t.Fatalf("got %v probabilities for %v weights", len(p), len(weights))
+		}
+		sum := 0.0
+		for i, x := range p {
+			if x < 0 || (weights[i] == 0 && x != 0) {
+				t.Fatalf("got probability %v for weight %v", x, weights[i])
+			}
+			sum += x
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Fatalf("probabilities sum to %v", sum)
+		}
+	})
+}
+
+func TestWeighted_ZeroWeight(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		s := rapid.Uint64().Draw(t, "s").(uint64)
+		r := rand.New(s)
+		weights := rapid.SliceOfN(rapid.SampledFrom([]float64{0, 0.5, 1, 3}), 1, -1).Draw(t, "weights").([]float64)
+		weights = append(weights, 1)
+		w, err := rand.NewWeighted(weights)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		for i := 0; i < tiny; i++ {
+			if ix := w.Sample(r); weights[ix] == 0 {
+				t.Fatalf("sampled index %v with zero weight", ix)
+			}
+		}
+	})
+}
+
+func TestWeighted_Distribution(t *testing.T) {
+	const n = 1 << 20
+	weights := []float64{1, 0, 2, 3, 0.5, 10, 1e-3, 7}
+	w, err := rand.NewWeighted(weights)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	r := rand.New(1)
+	counts := make([]int, len(weights))
+	for i := 0; i < n; i++ {
+		counts[w.Sample(r)]++
+	}
+	expected := w.Probabilities()
+	for i := range expected {
+		expected[i] *= n
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestWeightedSampleK(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := rapid.SliceOf(rapid.SampledFrom([]float64{0, 0.5, 1, 3, math.MaxFloat64})).Draw(t, "weights").([]float64)
+		k := rapid.IntRange(0, len(weights)).Draw(t, "k").(int)
+		r := rand.New(rapid.Uint64().Draw(t, "seed").(uint64))
+		p := rand.WeightedSampleK(r, weights, k)
+		if len(p) != k {
+			t.Fatalf("got %v indices instead of %v", len(p), k)
+		}
+		positive := 0
+		for _, w := range weights {
+			if w > 0 {
+				positive++
+			}
+		}
+		seen := map[int]bool{}
+		for i, j := range p {
+			if j < 0 || j >= len(weights) || seen[j] {
+				t.Fatalf("got invalid or repeated index %v in %v", j, p)
+			}
+			seen[j] = true
+			if (i < positive) != (weights[j] > 0) {
+				t.Fatalf("index %v with weight %v at position %v (%v positive weights)", j, weights[j], i, positive)
+			}
+		}
+	})
+}
+
+func TestWeightedSampleK_Invalid(t *testing.T) {
+	r := rand.New(1)
+	for _, c := range []struct {
+		weights []float64
+		k       int
+	}{
+		{[]float64{1, 2}, -1},
+		{[]float64{1, 2}, 3},
+		{[]float64{1, -1}, 1},
+		{[]float64{1, math.NaN()}, 1},
+		{[]float64{1, math.Inf(1)}, 1},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("got no panic for weights %v, k %v", c.weights, c.k)
+				}
+			}()
+			rand.WeightedSampleK(r, c.weights, c.k)
+		}()
+	}
+}
+
+func TestWeightedSampleK_Distribution(t *testing.T) {
+	const n = 1 << 18
+	weights := []float64{1, 2, 0, 3, 4}
+	r := rand.New(1)
+	counts := make([]int, len(weights)*len(weights))
+	for i := 0; i < n; i++ {
+		p := rand.WeightedSampleK(r, weights, 2)
+		counts[p[0]*len(weights)+p[1]]++
+	}
+	expected := make([]float64, len(counts))
+	for i, wi := range weights {
+		for j, wj := range weights {
+			if i != j {
+				expected[i*len(weights)+j] = n * wi / 10 * wj / (10 - wi)
+			}
+		}
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestWeightedSampleK_WideRange(t *testing.T) {
+	// the ratios of the small weights to the largest one underflow
+	const n = 1 << 16
+	weights := []float64{1e300, 1e-300, 1e-300, 1e-300, 2e-300}
+	r := rand.New(1)
+	counts := make([]int, len(weights))
+	for i := 0; i < n; i++ {
+		p := rand.WeightedSampleK(r, weights, 2)
+		if p[0] != 0 {
+			t.Fatalf("got %v before the largest weight", p)
+		}
+		counts[p[1]]++
+	}
+	checkChiSquared(t, counts, []float64{0, n / 5, n / 5, n / 5, 2 * n / 5})
+}
+
+func TestWeightedSampleK_ZeroWeights(t *testing.T) {
+	const n = 1 << 16
+	weights := []float64{0, 1, 0, 0}
+	r := rand.New(1)
+	counts := make([]int, len(weights))
+	for i := 0; i < n; i++ {
+		p := rand.WeightedSampleK(r, weights, 2)
+		if p[0] != 1 {
+			t.Fatalf("got %v before the only positive weight", p)
+		}
+		counts[p[1]]++
+	}
+	checkChiSquared(t, counts, []float64{n / 3, 0, n / 3, n / 3})
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	zigguratMaxLayers = 1 << 11 // layer index comes from the low 11 bits, which the scaled uniform number does not use
+	zigguratOneSided  = 1 << 53 // scale of the 53-bit uniform number for one-sided distributions
+	zigguratSymmetric = 1 << 52 // scale of the signed 53-bit uniform number for symmetric distributions
+)
+
+// ZigguratSpec describes a distribution for [NewZiggurat].
+type ZigguratSpec struct {
+	// PDF is the probability density function, up to a constant factor. It must be finite and positive at 0,
+	// and non-increasing on [0, ∞). Unless Symmetric is set, the distribution is restricted to [0, ∞).
+	PDF func(x float64) float64
+	// InversePDF returns x >= 0 such that PDF(x) = y, for y in (0, PDF(0)].
+	// If InversePDF is nil, it is computed numerically from PDF.
+	InversePDF func(y float64) float64
+	// TailArea returns the integral of PDF over [x, ∞).
+	TailArea func(x float64) float64
+	// Tail returns a pseudo-random number with the distribution restricted to [x, ∞),
+	// where x is [Ziggurat.TailStart], using r as the source of randomness.
+	Tail func(r *Rand, x float64) float64
+	// Symmetric makes the distribution symmetric around 0: PDF is used for |x|, and samples get a random sign.
+	Symmetric bool
+	// Layers is the number of layers of the ziggurat, a power of 2 between 2 and 2048.
+	// The standard normal and exponential distributions use 256.
+	Layers int
+	// TailStart and LayerArea, if positive, are used instead of the values computed from the other fields,
+	// to reproduce published tables that were built from rounded constants.
+	TailStart float64
+	LayerArea float64
+}
+
+// Ziggurat samples from a distribution with a decreasing (or symmetric and unimodal) density
+// using the ziggurat method by Marsaglia and Tsang, which [Rand.NormFloat64] and [Rand.ExpFloat64] use.
+//
+// The density is covered by Layers horizontal layers of equal area: the base layer includes the tail,
+// and every other one is a rectangle. Most samples need a single 64-bit uniform number
+// and a table lookup; the density is evaluated only near the edges of the rectangles, and the
+// tail sampler is called with probability of about TailArea(TailStart()) / Layers.
+//
+// Sharing a Ziggurat between goroutines requires the functions of its ZigguratSpec to be safe for concurrent use.
+type Ziggurat struct {
+	pdf       func(float64) float64
+	tail      func(*Rand, float64) float64
+	symmetric bool
+	mask      uint64
+	r         float64 // start of the tail
+	v         float64 // area of each layer
+	k         []uint64
+	w         []float64
+	f         []float64
+}
+
+// NewZiggurat builds the ziggurat tables for the distribution described by spec.
+// It returns an error if a function is missing, the number of layers is invalid,
+// or the tables can not be built, which happens if PDF is not decreasing or TailArea is inconsistent with it.
+func NewZiggurat(spec ZigguratSpec) (*Ziggurat, error) {
+	if spec.PDF == nil || spec.TailArea == nil || spec.Tail == nil {
+		return nil, errors.New("rand: ziggurat specification without PDF, TailArea or Tail")
+	}
+	n := spec.Layers
+	if n < 2 || n > zigguratMaxLayers || n&(n-1) != 0 {
+		return nil, fmt.Errorf("rand: invalid number of ziggurat layers %v", n)
+	}
+	f0 := spec.PDF(0)
+	if !isFinite(f0) || f0 <= 0 {
+		return nil, fmt.Errorf("rand: invalid ziggurat density %v at 0", f0)
+	}
+	inv := spec.InversePDF
+	if inv == nil {
+		inv = func(y float64) float64 { return inversePDF(spec.PDF, y) }
+	}
+	b := zigguratBuilder{pdf: spec.PDF, inv: inv, tailArea: spec.TailArea, f0: f0, n: n}
+	r, v := spec.TailStart, spec.LayerArea
+	if !(r > 0) {
+		var ok bool
+		if r, ok = b.solve(); !ok {
+			return nil, errors.New("rand: can not build ziggurat tables for the density")
+		}
+	}
+	if !(v > 0) {
+		v = b.area(r)
+	}
+	if !isFinite(r) || !isFinite(v) {
+		return nil, fmt.Errorf("rand: invalid ziggurat tail start %v or layer area %v", r, v)
+	}
+
+	m := float64(zigguratOneSided)
+	if spec.Symmetric {
+		m = zigguratSymmetric
+	}
+	z := &Ziggurat{
+		pdf:       spec.PDF,
+		tail:      spec.Tail,
+		symmetric: spec.Symmetric,
+		mask:      uint64(n - 1),
+		r:         r,
+		v:         v,
+		k:         make([]uint64, n),
+		w:         make([]float64, n),
+		f:         make([]float64, n),
+	}
+	// the same computation as the one that produced the tables of NormFloat64 and ExpFloat64
+	q := z.v / spec.PDF(r)
+	z.k[0] = uint64((r / q) * m)
+	z.k[1] = 0
+	z.w[0] = q / m
+	z.w[n-1] = r / m
+	z.f[0] = f0
+	z.f[n-1] = spec.PDF(r)
+	x, prev := r, r
+	for i := n - 2; i >= 1; i-- {
+		x = inv(z.v/x + spec.PDF(x))
+		z.k[i+1] = uint64((x / prev) * m)
+		prev = x
+		z.f[i] = spec.PDF(x)
+		z.w[i] = x / m
+	}
+	return z, nil
+}
+
+// zigguratBuilder finds the start of the tail for which the layers exactly cover the density.
+type zigguratBuilder struct {
+	pdf      func(float64) float64
+	inv      func(float64) float64
+	tailArea func(float64) float64
+	f0       float64
+	n        int
+}
+
+// area returns the area of each layer for the tail starting at r.
+func (b *zigguratBuilder) area(r float64) float64 {
+	return r*b.pdf(r) + b.tailArea(r)
+}
+
+// overshoots reports whether the layers for the tail starting at r reach the top of the density
+// before all of them are stacked; this is the case for all r below the solution.
+func (b *zigguratBuilder) overshoots(r float64) bool {
+	v := b.area(r)
+	x := r
+	for i := b.n - 1; i >= 1; i-- {
+		y := v/x + b.pdf(x)
+		if !(y < b.f0) {
+			return true
+		}
+		if i > 1 {
+			x = b.inv(y)
+		}
+	}
+	return false
+}
+
+func (b *zigguratBuilder) solve() (float64, bool) {
+	lo, hi := 1.0, 1.0
+	for b.overshoots(hi) {
+		lo, hi = hi, 2*hi
+		if math.IsInf(hi, 0) {
+			return 0, false
+		}
+	}
+	for lo == hi || !b.overshoots(lo) {
+		lo /= 2
+		if lo == 0 {
+			return 0, false
+		}
+	}
+	for {
+		mid := lo + (hi-lo)/2
+		if mid <= lo || mid >= hi {
+			break
+		}
+		if b.overshoots(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	if v := b.area(hi); !isFinite(v) || v <= 0 {
+		return 0, false
+	}
+	return hi, true
+}
+
+// inversePDF returns x >= 0 such that pdf(x) = y for non-increasing pdf, by bisection.
+func inversePDF(pdf func(float64) float64, y float64) float64 {
+	lo, hi := 0.0, 1.0
+	for pdf(hi) >= y && !math.IsInf(hi, 0) {
+		lo, hi = hi, 2*hi
+	}
+	for {
+		mid := lo + (hi-lo)/2
+		if mid <= lo || mid >= hi {
+			return mid
+		}
+		if pdf(mid) >= y {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+}
+
+// TailStart returns the start of the tail, which is sampled by the Tail function of the ZigguratSpec.
+func (z *Ziggurat) TailStart() float64 {
+	return z.r
+}
+
+// LayerArea returns the area of each layer, in units of the PDF of the ZigguratSpec.
+func (z *Ziggurat) LayerArea() float64 {
+	return z.v
+}
+
+// Tables returns copies of the ziggurat tables, in the format of the tables of [Rand.NormFloat64]
+// (for symmetric distributions) and [Rand.ExpFloat64] (otherwise) with 256 layers:
+// k are the acceptance thresholds for the scaled uniform numbers, w are the scales of the layers,
+// and f are the values of PDF at the layer boundaries.
+func (z *Ziggurat) Tables() (k []uint64, w []float64, f []float64) {
+	return append([]uint64(nil), z.k...), append([]float64(nil), z.w...), append([]float64(nil), z.f...)
+}
+
+// Sample returns a pseudo-random number with the distribution, using r as the source of randomness.
+func (z *Ziggurat) Sample(r *Rand) float64 {
+	if z.symmetric {
+		return z.sampleSymmetric(r)
+	}
+	for {
+		v := r.Uint64()
+		j := v >> 11
+		i := v & z.mask
+		x := float64(j) * z.w[i]
+		if j < z.k[i] {
+			return x
+		}
+		if i == 0 {
+			return z.tail(r, z.r)
+		}
+		if z.f[i]+r.Float64()*(z.f[i-1]-z.f[i]) < z.pdf(x) {
+			return x
+		}
+	}
+}
+
+func (z *Ziggurat) sampleSymmetric(r *Rand) float64 {
+	for {
+		v := r.Uint64()
+		j := int64(v) >> 11 // Possibly negative
+		i := v & z.mask
+		x := float64(j) * z.w[i]
+		if absInt64(j) < z.k[i] {
+			return x
+		}
+		if i == 0 {
+			t := z.tail(r, z.r)
+			if j > 0 {
+				return t
+			}
+			return -t
+		}
+		if z.f[i]+r.Float64()*(z.f[i-1]-z.f[i]) < z.pdf(math.Abs(x)) {
+			return x
+		}
+	}
+}
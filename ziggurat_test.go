@@ -0,0 +1,186 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kokizzu/rand"
+)
+
+var (
+	normalZiggurat = rand.ZigguratSpec{
+		PDF:        func(x float64) float64 { return math.Exp(-x * x / 2) },
+		InversePDF: func(y float64) float64 { return math.Sqrt(-2 * math.Log(y)) },
+		TailArea:   func(x float64) float64 { return math.Sqrt(math.Pi/2) * math.Erfc(x/math.Sqrt2) },
+		Tail: func(r *rand.Rand, x0 float64) float64 {
+			for {
+				x := -math.Log(r.Float64()) / x0
+				y := -math.Log(r.Float64())
+				if y+y >= x*x {
+					return x0 + x
+				}
+			}
+		},
+		Symmetric: true,
+		Layers:    256,
+	}
+	exponentialZiggurat = rand.ZigguratSpec{
+		PDF:        func(x float64) float64 { return math.Exp(-x) },
+		InversePDF: func(y float64) float64 { return -math.Log(y) },
+		TailArea:   func(x float64) float64 { return math.Exp(-x) },
+		Tail:       func(r *rand.Rand, x0 float64) float64 { return x0 - math.Log(r.Float64()) },
+		Layers:     256,
+	}
+)
+
+func checkZigguratTables(t *testing.T, name string, spec rand.ZigguratSpec, r0 float64, k0 [256]uint64, w0 [256]float64, f0 [256]float64, tol float64) {
+	t.Helper()
+	near := func(a float64, b float64) bool { return math.Abs(a-b) <= tol*math.Max(math.Abs(a), math.Abs(b)) }
+	for _, numeric := range []bool{false, true} {
+		if numeric {
+			// rounding errors of the numerical inverse are amplified by the recursion over the layers
+			spec.InversePDF = nil
+			tol = math.Max(tol, 1e-9)
+		}
+		z, err := rand.NewZiggurat(spec)
+		if err != nil {
+			t.Fatalf("%v: got unexpected error: %v", name, err)
+		}
+		if !near(z.TailStart(), r0) {
+			t.Errorf("%v: got tail start %v instead of %v", name, z.TailStart(), r0)
+		}
+		k, w, f := z.Tables()
+		for i := range k {
+			if !near(float64(k[i]), float64(k0[i])) || !near(w[i], w0[i]) || !near(f[i], f0[i]) {
+				t.Errorf("%v: got %#x, %v, %v at index %v instead of %#x, %v, %v", name, k[i], w[i], f[i], i, k0[i], w0[i], f0[i])
+			}
+		}
+	}
+}
+
+func TestZiggurat_NormalTables(t *testing.T) {
+	rn, kn, wn, fn := rand.GetNormalDistributionParameters()
+	// the tables are built from the layer area published by Marsaglia and Tsang, which is rounded to 12 digits
+	spec := normalZiggurat
+	spec.TailStart, spec.LayerArea = rn, 0.00492867323399
+	checkZigguratTables(t, "normal", spec, rn, kn, wn, fn, 1e-12)
+	checkZigguratTables(t, "normal", normalZiggurat, rn, kn, wn, fn, 1e-9)
+}
+
+func TestZiggurat_ExponentialTables(t *testing.T) {
+	re, ke, we, fe := rand.GetExponentialDistributionParameters()
+	spec := exponentialZiggurat
+	spec.TailStart, spec.LayerArea = re, 0.0039496598225815571993
+	checkZigguratTables(t, "exponential", spec, re, ke, we, fe, 1e-12)
+	checkZigguratTables(t, "exponential", exponentialZiggurat, re, ke, we, fe, 1e-12)
+}
+
+func checkZigguratSamples(t *testing.T, name string, z *rand.Ziggurat, d rand.Continuous) {
+	t.Helper()
+	const bins = 64
+	counts := make([]int, bins)
+	expected := make([]float64, bins)
+	for i := range expected {
+		expected[i] = distSamples / bins
+	}
+	r := rand.New(1)
+	for i := 0; i < distSamples; i++ {
+		b := int(d.CDF(z.Sample(r)) * bins)
+		if b == bins {
+			b--
+		}
+		counts[b]++
+	}
+	checkChiSquared(t, counts, expected)
+}
+
+func TestZiggurat_Sample(t *testing.T) {
+	for _, layers := range []int{2, 16, 256, 2048} {
+		spec := normalZiggurat
+		spec.Layers = layers
+		z, err := rand.NewZiggurat(spec)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		n, _ := rand.NewNormal(0, 1)
+		checkZigguratSamples(t, "normal", z, n)
+
+		spec = exponentialZiggurat
+		spec.Layers = layers
+		z, err = rand.NewZiggurat(spec)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		e, _ := rand.NewExponential(1)
+		checkZigguratSamples(t, "exponential", z, e)
+	}
+}
+
+func TestZiggurat_Numeric(t *testing.T) {
+	// Lomax (Pareto type II) distribution with α = 3, without a closed-form inverse density
+	const alpha = 3
+	z, err := rand.NewZiggurat(rand.ZigguratSpec{
+		PDF:      func(x float64) float64 { return math.Pow(1+x, -alpha-1) },
+		TailArea: func(x float64) float64 { return math.Pow(1+x, -alpha) / alpha },
+		Tail: func(r *rand.Rand, x0 float64) float64 {
+			return (1+x0)*math.Pow(1-r.Float64(), -1.0/alpha) - 1
+		},
+		Layers: 128,
+	})
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	p, _ := rand.NewPareto(1, alpha)
+	checkZigguratSamples(t, "lomax", z, shifted{p, -1})
+}
+
+// shifted is a continuous distribution shifted by a constant, for its CDF.
+type shifted struct {
+	rand.Continuous
+	shift float64
+}
+
+func (d shifted) CDF(x float64) float64 {
+	return d.Continuous.CDF(x - d.shift)
+}
+
+func TestZiggurat_Invalid(t *testing.T) {
+	for _, layers := range []int{0, 1, 3, 100, 4096} {
+		spec := exponentialZiggurat
+		spec.Layers = layers
+		if _, err := rand.NewZiggurat(spec); err == nil {
+			t.Errorf("got no error for %v layers", layers)
+		}
+	}
+	spec := exponentialZiggurat
+	spec.Tail = nil
+	if _, err := rand.NewZiggurat(spec); err == nil {
+		t.Errorf("got no error without Tail")
+	}
+	spec = exponentialZiggurat
+	spec.PDF = func(x float64) float64 { return math.Inf(1) }
+	if _, err := rand.NewZiggurat(spec); err == nil {
+		t.Errorf("got no error for infinite density")
+	}
+	spec = exponentialZiggurat
+	spec.InversePDF = nil
+	spec.PDF = func(x float64) float64 { return math.Exp(x) }
+	if _, err := rand.NewZiggurat(spec); err == nil {
+		t.Errorf("got no error for increasing density")
+	}
+}
+
+func BenchmarkZiggurat_Normal(b *testing.B) {
+	z, _ := rand.NewZiggurat(normalZiggurat)
+	r := rand.New(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sinkFloat64 = z.Sample(r)
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2022 Gregory Petrosyan <gregory.petrosyan@gmail.com>
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package rand
+
+import (
+	"fmt"
+	"math"
+)
+
+var _ Discrete = (*LatestZipf)(nil)
+
+func (z *Zipf) initDistribution(imax uint64) {
+	z.imaxInt = imax
+	z.norm = powerSum(z.q, z.v, 0, z.imax)
+}
+
+// hSmall is the integral of (v + x) ** (-s) for s <= 1, shifted to be 0 at x = 1-v, in a form
+// that does not lose precision for s close to 1, and is log(v + x) for s = 1.
+func (z *Zipf) hSmall(x float64) float64 {
+	l := math.Log(z.v + x)
+	return expm1Ratio(z.oneminusQ*l) * l
+}
+
+// hinvSmall is the inverse of hSmall.
+func (z *Zipf) hinvSmall(x float64) float64 {
+	return math.Exp(log1pRatio(z.oneminusQ*x)*x) - z.v
+}
+
+// expm1Ratio returns (e^x - 1) / x, which is 1 for x = 0.
+func expm1Ratio(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Expm1(x) / x
+}
+
+// log1pRatio returns log(1 + x) / x, which is 1 for x = 0.
+func log1pRatio(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Log1p(x) / x
+}
+
+// Grow returns the Zipf distribution with the same s and v (and generator, if any) over [0, imax],
+// for keyspaces that expand over time. It takes O(1) time, and leaves z unchanged.
+// It panics if imax is less than the current maximum value.
+func (z *Zipf) Grow(imax uint64) *Zipf {
+	if imax < z.imaxInt {
+		panic("invalid argument to Grow")
+	}
+	g := *z
+	g.setImax(imax)
+	return &g
+}
+
+// PMF returns the probability that a sample is equal to k.
+func (z *Zipf) PMF(k uint64) float64 {
+	if k > z.imaxInt {
+		return 0
+	}
+	return math.Pow(z.v+float64(k), -z.q) / z.norm
+}
+
+// CDF returns the probability that a sample is less than or equal to k.
+func (z *Zipf) CDF(k uint64) float64 {
+	if k >= z.imaxInt {
+		return 1
+	}
+	return math.Min(powerSum(z.q, z.v, 0, float64(k))/z.norm, 1)
+}
+
+// Quantile returns the smallest k such that CDF(k) >= p. It panics if p is not in [0, 1].
+func (z *Zipf) Quantile(p float64) uint64 {
+	checkQuantile(p)
+	return searchQuantile(z.CDF, p, z.imaxInt)
+}
+
+// Mean returns the expected value.
+func (z *Zipf) Mean() float64 {
+	return powerSum(z.q-1, z.v, 0, z.imax)/z.norm - z.v
+}
+
+// Variance returns the variance.
+func (z *Zipf) Variance() float64 {
+	m := z.Mean() + z.v
+	return math.Max(powerSum(z.q-2, z.v, 0, z.imax)/z.norm-m*m, 0)
+}
+
+// ScrambledZipf is the Zipf distribution over n keys, with ranks scattered across [0, n) by a pseudo-random
+// [Permutation], like the scrambled Zipfian generator of YCSB: the most popular keys are not clustered near 0,
+// while the popularity of each key still follows the Zipf distribution exactly.
+type ScrambledZipf struct {
+	z Zipf
+	p *Permutation
+}
+
+// NewScrambledZipf returns the Zipf distribution over keys in [0, n), where the key of rank k
+// has probability proportional to (v + k) ** (-s), and ranks are assigned to keys by a permutation
+// with keys taken from r. It returns an error unless s > 0, v >= 1 and n > 0.
+func NewScrambledZipf(r *Rand, s float64, v float64, n uint64) (*ScrambledZipf, error) {
+	if n == 0 {
+		return nil, fmt.Errorf("rand: invalid scrambled Zipf distribution key count %v", n)
+	}
+	z, err := NewZipfDistribution(s, v, n-1)
+	if err != nil {
+		return nil, err
+	}
+	return &ScrambledZipf{z: *z, p: NewPermutation(r, n)}, nil
+}
+
+// Len returns n, the number of keys.
+func (d *ScrambledZipf) Len() uint64 {
+	return d.p.Len()
+}
+
+// Rank returns the rank of key k, 0 being the most popular. It panics if k >= n.
+func (d *ScrambledZipf) Rank(k uint64) uint64 {
+	return d.p.Index(k)
+}
+
+// Sample returns a pseudo-random key, using r as the source of randomness.
+func (d *ScrambledZipf) Sample(r *Rand) uint64 {
+	return d.p.At(d.z.Sample(r))
+}
+
+// PMF returns the probability that a sample is equal to k.
+func (d *ScrambledZipf) PMF(k uint64) float64 {
+	if k >= d.p.Len() {
+		return 0
+	}
+	return d.z.PMF(d.p.Index(k))
+}
+
+// LatestZipf is the Zipf distribution over n keys skewed toward the most recently inserted ones,
+// like the latest generator of YCSB: key n-1 is the most popular, key n-2 is the second most popular, and so on.
+// The keyspace can be extended with [LatestZipf.Grow] as keys are inserted.
+type LatestZipf struct {
+	z Zipf
+}
+
+// NewLatestZipf returns the Zipf distribution over keys in [0, n), where key n-1-k
+// has probability proportional to (v + k) ** (-s). It returns an error unless s > 0, v >= 1 and n > 0.
+func NewLatestZipf(s float64, v float64, n uint64) (*LatestZipf, error) {
+	if n == 0 {
+		return nil, fmt.Errorf("rand: invalid latest Zipf distribution key count %v", n)
+	}
+	z, err := NewZipfDistribution(s, v, n-1)
+	if err != nil {
+		return nil, err
+	}
+	return &LatestZipf{z: *z}, nil
+}
+
+// Len returns n, the number of keys.
+func (d *LatestZipf) Len() uint64 {
+	return d.z.imaxInt + 1
+}
+
+// Grow returns the distribution with the same s and v over the keyspace [0, n), where key n-1 is the most popular.
+// It takes O(1) time, and leaves d unchanged. It panics if n is less than the current number of keys.
+func (d *LatestZipf) Grow(n uint64) *LatestZipf {
+	if n < d.Len() {
+		panic("invalid argument to Grow")
+	}
+	g := *d
+	g.z.setImax(n - 1)
+	return &g
+}
+
+// Sample returns a pseudo-random key, using r as the source of randomness.
+func (d *LatestZipf) Sample(r *Rand) uint64 {
+	return d.z.imaxInt - d.z.Sample(r)
+}
+
+// PMF returns the probability that a sample is equal to k.
+func (d *LatestZipf) PMF(k uint64) float64 {
+	if k > d.z.imaxInt {
+		return 0
+	}
+	return d.z.PMF(d.z.imaxInt - k)
+}
+
+// CDF returns the probability that a sample is less than or equal to k.
+func (d *LatestZipf) CDF(k uint64) float64 {
+	if k >= d.z.imaxInt {
+		return 1
+	}
+	return math.Max(1-d.z.CDF(d.z.imaxInt-k-1), 0)
+}
+
+// Quantile returns the smallest k such that CDF(k) >= p. It panics if p is not in [0, 1].
+func (d *LatestZipf) Quantile(p float64) uint64 {
+	checkQuantile(p)
+	return searchQuantile(d.CDF, p, d.z.imaxInt)
+}
+
+// Mean returns the expected value.
+func (d *LatestZipf) Mean() float64 {
+	return d.z.imax - d.z.Mean()
+}
+
+// Variance returns the variance.
+func (d *LatestZipf) Variance() float64 {
+	return d.z.Variance()
+}
+
+// searchQuantile returns the smallest k in [0, max] such that cdf(k) >= p,
+// for non-decreasing cdf with cdf(max) = 1.
+func searchQuantile(cdf func(uint64) float64, p float64, max uint64) uint64 {
+	lo, hi := uint64(0), max
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if cdf(mid) >= p {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+// eulerMaclaurin are the coefficients B_2j / (2j)! of the Euler-Maclaurin formula.
+var eulerMaclaurin = [...]float64{1.0 / 12, -1.0 / 720, 1.0 / 30240, -1.0 / 1209600, 1.0 / 47900160}
+
+// powerSum returns the sum of (v + i) ** (-t) for integer i ∈ [lo, hi], for v + lo > 0.
+// The first terms are summed directly, and the rest is approximated with the Euler-Maclaurin formula,
+// so the time does not depend on the number of terms.
+func powerSum(t float64, v float64, lo float64, hi float64) float64 {
+	const direct = 32
+	sum := 0.0
+	i := lo
+	for ; i <= hi && i < lo+direct; i++ {
+		sum += math.Pow(v+i, -t)
+	}
+	if i > hi {
+		return sum
+	}
+	a, b := v+i, v+hi
+	// the integral (b ** (1-t) - a ** (1-t)) / (1-t), without cancellation for t close to 1
+	l := math.Log(b / a)
+	sum += math.Pow(a, 1-t) * expm1Ratio((1-t)*l) * l
+	sum += (math.Pow(a, -t) + math.Pow(b, -t)) / 2
+	d := -t // coefficient of the (2j-1)-th derivative of x ** (-t), which is d * x ** (-t-2j+1)
+	for j, c := range eulerMaclaurin {
+		m := float64(2*j + 1)
+		if j > 0 {
+			d *= (-t - m + 2) * (-t - m + 1)
+		}
+		sum += c * d * (math.Pow(b, -t-m) - math.Pow(a, -t-m))
+	}
+	return sum
+}